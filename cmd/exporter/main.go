@@ -14,8 +14,10 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	"github.com/fabienpiette/docker-stats-exporter/internal/cgroup"
 	"github.com/fabienpiette/docker-stats-exporter/internal/collector"
 	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/internal/procfs"
 	"github.com/fabienpiette/docker-stats-exporter/internal/server"
 	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
 )
@@ -63,46 +65,46 @@ func main() {
 	collector.Commit = commit
 	collector.BuildDate = buildDate
 
-	// Create Docker client
-	dockerClient, err := docker.NewClient(cfg.Docker, cfg.Collection.Timeout)
-	if err != nil {
-		log.Fatalf("Failed to create Docker client: %v", err)
-	}
-	defer dockerClient.Close()
+	// Background work (inventory, snapshots, ...) stops when this context is canceled
+	ctx, cancelBackground := context.WithCancel(context.Background())
+	defer cancelBackground()
 
-	// Verify Docker connectivity
-	if err := dockerClient.Ping(context.Background()); err != nil {
-		log.Warnf("Docker daemon not reachable at startup: %v", err)
-	} else {
-		log.Info("Successfully connected to Docker daemon")
-	}
-
-	// Create filter
-	filter, err := docker.NewFilter(cfg.Collection.Filters)
-	if err != nil {
-		log.Fatalf("Failed to create container filter: %v", err)
-	}
-
-	// Create cache
-	cache := collector.NewStatsCache(cfg.Metrics.Cache.TTL, cfg.Metrics.Cache.Enabled)
-
-	// Create Prometheus registry and register collectors
+	// One client, filter, cache and collector set per Docker endpoint. With
+	// several endpoints, each set registers through a wrapper that adds its
+	// docker_host label, so one unreachable daemon only affects its own series.
 	registry := prometheus.NewRegistry()
+	var dockerClients []*docker.Client
 
-	if cfg.Collection.Collectors.Container {
-		cc := collector.NewContainerCollector(dockerClient, filter, cache, cfg)
-		registry.MustRegister(cc)
-		log.Info("Container collector registered")
-	}
+	for _, ep := range cfg.Docker.ResolvedEndpoints() {
+		var reg prometheus.Registerer = registry
+		if cfg.Docker.MultiHost() {
+			reg = prometheus.WrapRegistererWith(prometheus.Labels{"docker_host": ep.Name}, registry)
+		}
 
-	if cfg.Collection.Collectors.System {
-		sc := collector.NewSystemCollector(dockerClient, cfg)
-		registry.MustRegister(sc)
-		log.Info("System collector registered")
+		dockerClient, err := setupEndpoint(ctx, ep, cfg, reg)
+		if err != nil {
+			log.Fatalf("Failed to set up Docker endpoint %q: %v", ep.Name, err)
+		}
+		defer dockerClient.Close()
+		dockerClients = append(dockerClients, dockerClient)
 	}
 
 	// Start HTTP server
-	srv := server.NewServer(cfg.Server, registry, dockerClient)
+	srv := server.NewServer(cfg.Server, registry, dockerClients)
+
+	// Multi-target probing: clients for probed daemons are pooled and closed
+	// once idle
+	if cfg.Probe.Enabled {
+		pool := docker.NewClientPool(cfg.Collection.Timeout, cfg.Probe.IdleTimeout)
+		go pool.Run(ctx)
+
+		probe, err := server.NewProbeHandler(cfg, pool)
+		if err != nil {
+			log.Fatalf("Failed to set up probe endpoint: %v", err)
+		}
+		srv.Handle(cfg.Probe.Path, probe)
+		log.WithField("path", cfg.Probe.Path).Info("Probe endpoint enabled")
+	}
 
 	go func() {
 		if err := srv.Start(); err != nil && err.Error() != "http: Server closed" {
@@ -118,17 +120,203 @@ func main() {
 	sig := <-sigChan
 	log.WithField("signal", sig.String()).Info("Received shutdown signal")
 
+	cancelBackground()
+
 	// Graceful shutdown with 10s timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.WithError(err).Error("Server shutdown error")
 	}
 
 	log.Info("Docker Stats Exporter stopped")
 }
 
+// setupEndpoint creates the client, container and stats sources, and
+// collectors for one Docker daemon, and registers the collectors with reg.
+// Background goroutines stop when ctx is canceled; the caller closes the
+// returned client.
+func setupEndpoint(ctx context.Context, ep config.DockerEndpoint, cfg *config.Config, reg prometheus.Registerer) (*docker.Client, error) {
+	logger := log.NewEntry(log.StandardLogger())
+	if ep.Name != "" {
+		logger = logger.WithField("docker_host", ep.Name)
+	}
+
+	// Create Docker client
+	dockerClient, err := docker.NewClient(ep.DockerConfig(), cfg.Collection.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("creating Docker client: %w", err)
+	}
+
+	// Verify Docker connectivity
+	if err := dockerClient.Ping(ctx); err != nil {
+		logger.Warnf("Docker daemon not reachable at startup: %v", err)
+	} else {
+		logger.Info("Successfully connected to Docker daemon")
+	}
+
+	// Create filter, per endpoint if it overrides the global one
+	filtersCfg := cfg.Collection.Filters
+	if ep.Filters != nil {
+		filtersCfg = *ep.Filters
+	}
+	filter, err := docker.NewFilter(filtersCfg)
+	if err != nil {
+		dockerClient.Close()
+		return nil, fmt.Errorf("creating container filter: %w", err)
+	}
+
+	// Create cache
+	cache := collector.NewStatsCache(cfg.Metrics.Cache.TTL, cfg.Metrics.Cache.Enabled)
+
+	// Block device names and container routing tables come from the
+	// exporter's host, so they are only read when it watches a single,
+	// presumably local, daemon
+	var devices *docker.DeviceNames
+	if cfg.Host.DevBlockRoot != "" && len(cfg.Docker.Endpoints) <= 1 {
+		devices = docker.NewDeviceNames(cfg.Host.DevBlockRoot)
+	}
+	var proc *procfs.Reader
+	if cfg.Host.ProcRoot != "" && len(cfg.Docker.Endpoints) <= 1 {
+		proc = procfs.NewReader(cfg.Host.ProcRoot)
+	}
+
+	// The host's cgroup hierarchy, for the cgroup stats source and PSI
+	var cgroupReader *cgroup.Reader
+	if cfg.Collection.Stats.Source == config.StatsSourceCgroup || cfg.Collection.Collectors.Pressure {
+		cgroupReader, err = cgroup.NewReader(cfg.Host.CgroupRoot)
+		if err != nil {
+			dockerClient.Close()
+			return nil, fmt.Errorf("opening cgroup hierarchy: %w", err)
+		}
+	}
+
+	// Container source: either list+inspect per scrape, or the event-driven inventory
+	var containerSource collector.DockerClient = dockerClient
+	var lister docker.ContainerLister = dockerClient
+	if cfg.Collection.Inventory.Enabled {
+		inventory := docker.NewInventory(dockerClient)
+		go inventory.Run(ctx)
+		containerSource = inventory
+		lister = inventory
+		logger.Info("Event-driven container inventory enabled")
+	}
+
+	// Stats source: one-shot requests per scrape (default), persistent
+	// streams, or the cgroup filesystem
+	switch cfg.Collection.Stats.Source {
+	case config.StatsSourceStream:
+		streamer := docker.NewStatsStreamer(dockerClient, lister, 2*cfg.Collection.Interval)
+		go func() {
+			<-ctx.Done()
+			streamer.Close()
+		}()
+		containerSource = streamer
+		logger.Info("Streaming stats readers enabled")
+	case config.StatsSourceCgroup:
+		containerSource = cgroup.NewSource(cgroupReader, lister, dockerClient)
+		logger.WithFields(log.Fields{
+			"root":    cfg.Host.CgroupRoot,
+			"version": cgroupReader.Version(),
+		}).Info("Reading container stats from cgroup filesystem")
+	}
+
+	var collectors []prometheus.Collector
+
+	if cfg.Collection.Collectors.Container {
+		collectors = append(collectors, collector.NewContainerCollector(containerSource, filter, cache, devices, docker.NewHostResources(dockerClient), proc, cfg))
+		logger.Info("Container collector registered")
+	}
+
+	if cfg.Collection.Collectors.System {
+		collectors = append(collectors, collector.NewSystemCollector(dockerClient, cfg))
+		logger.Info("System collector registered")
+	}
+
+	if cfg.Collection.Collectors.Swarm {
+		collectors = append(collectors, collector.NewSwarmCollector(dockerClient, cfg))
+		logger.Info("Swarm collector registered")
+	}
+
+	if cfg.Collection.Collectors.Images {
+		imageFilter, err := docker.NewImageFilter(cfg.Collection.Images)
+		if err != nil {
+			dockerClient.Close()
+			return nil, fmt.Errorf("creating image filter: %w", err)
+		}
+		collectors = append(collectors, collector.NewImageCollector(dockerClient, imageFilter, cfg))
+		logger.Info("Image collector registered")
+	}
+
+	if cfg.Collection.Collectors.Networks {
+		collectors = append(collectors, collector.NewNetworkCollector(dockerClient, cfg))
+		logger.Info("Network collector registered")
+	}
+
+	if cfg.Collection.Collectors.Pressure {
+		if cgroupReader.Version() == cgroup.V2 {
+			collectors = append(collectors, collector.NewPressureCollector(lister, cgroupReader, filter, cfg))
+			logger.Info("Pressure collector registered")
+		} else {
+			logger.Warn("Pressure stall information requires cgroup v2, pressure collector disabled")
+		}
+	}
+
+	if cfg.Collection.Collectors.Sockets {
+		collectors = append(collectors, collector.NewSocketCollector(lister, proc, filter, cfg))
+		logger.WithField("root", cfg.Host.ProcRoot).Info("Socket collector registered")
+	}
+
+	// With a non-zero interval, collect in the background and serve snapshots
+	if cfg.Collection.Interval > 0 {
+		snap := collector.NewSnapshotCollector(cfg.Collection.Interval, collectors...)
+		go snap.Run(ctx)
+		collectors = []prometheus.Collector{snap}
+		logger.WithField("interval", cfg.Collection.Interval.String()).Info("Background collection enabled")
+	}
+
+	// Disk usage and container sizes refresh on their own, slower schedules,
+	// and event counters follow the events stream; none are part of the
+	// snapshot pass
+	if cfg.Collection.Collectors.DiskUsage {
+		du := collector.NewDiskUsageCollector(dockerClient, cfg)
+		go du.Run(ctx)
+		collectors = append(collectors, du)
+		logger.WithField("interval", cfg.Collection.DiskUsage.Interval.String()).Info("Disk usage collector registered")
+	}
+
+	if cfg.Collection.Collectors.ContainerSize {
+		sizes := collector.NewContainerSizeCollector(dockerClient, filter, cfg)
+		go sizes.Run(ctx)
+		collectors = append(collectors, sizes)
+		logger.WithField("interval", cfg.Collection.ContainerSize.Interval.String()).Info("Container size collector registered")
+	}
+
+	// OOM and lifecycle counters share one events subscription
+	if cfg.Collection.Collectors.OOMEvents || cfg.Collection.Collectors.Events {
+		tracker := docker.NewEventTracker(dockerClient, filter)
+		go tracker.Run(ctx)
+		if cfg.Collection.Collectors.OOMEvents {
+			collectors = append(collectors, collector.NewOOMCollector(tracker))
+			logger.Info("OOM events collector registered")
+		}
+		if cfg.Collection.Collectors.Events {
+			collectors = append(collectors, collector.NewEventsCollector(tracker))
+			logger.Info("Container events collector registered")
+		}
+	}
+
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			dockerClient.Close()
+			return nil, fmt.Errorf("registering collector: %w", err)
+		}
+	}
+
+	return dockerClient, nil
+}
+
 func initLogger(cfg config.LoggingConfig) {
 	// Set level
 	level, err := log.ParseLevel(cfg.Level)
@@ -0,0 +1,185 @@
+// Package cgroup reads container resource usage straight from the cgroup
+// filesystem (v1 or v2), bypassing the Docker stats API. It produces the same
+// types.StatsJSON the daemon would send, so parsing and emission stay shared
+// with the API-backed sources.
+package cgroup
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+)
+
+// Hierarchy versions returned by Reader.Version.
+const (
+	V1 = 1
+	V2 = 2
+)
+
+// Reader reads container stats from a cgroup hierarchy mounted at root
+// (usually the host's /sys/fs/cgroup, bind-mounted into the exporter).
+type Reader struct {
+	root    string
+	version int
+}
+
+// NewReader creates a reader for the hierarchy at root, detecting whether it
+// is cgroup v2 (unified) or v1 (one hierarchy per controller). Hybrid hosts,
+// which mount v2 only for systemd, are treated as v1.
+func NewReader(root string) (*Reader, error) {
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		return &Reader{root: root, version: V2}, nil
+	}
+	if fi, err := os.Stat(filepath.Join(root, "memory")); err == nil && fi.IsDir() {
+		return &Reader{root: root, version: V1}, nil
+	}
+	return nil, fmt.Errorf("no cgroup v1 or v2 hierarchy found at %s", root)
+}
+
+// Version reports the detected hierarchy version (V1 or V2).
+func (r *Reader) Version() int {
+	return r.version
+}
+
+// Read returns the current stats for a container. Memory limit is left at 0
+// (v2) or at the kernel's "unlimited" sentinel (v1) when the cgroup has no
+// limit; callers substitute host memory, matching what the Docker API reports.
+func (r *Reader) Read(ctr *docker.Container) (*types.StatsJSON, error) {
+	rel, err := r.resolve(ctr)
+	if err != nil {
+		return nil, err
+	}
+	if r.version == V1 {
+		return readV1(r.root, rel)
+	}
+	return readV2(filepath.Join(r.root, rel))
+}
+
+// Path resolves the cgroup directory of a container. On v1 this is the
+// directory in the memory hierarchy.
+func (r *Reader) Path(ctr *docker.Container) (string, error) {
+	rel, err := r.resolve(ctr)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(r.base(), rel), nil
+}
+
+// base is the directory container cgroups are probed under.
+func (r *Reader) base() string {
+	if r.version == V1 {
+		return filepath.Join(r.root, "memory")
+	}
+	return r.root
+}
+
+// resolve finds a container's cgroup path relative to the hierarchy root.
+// Both the systemd (system.slice/docker-<id>.scope) and cgroupfs (docker/<id>)
+// driver layouts are probed, honoring a custom --cgroup-parent.
+func (r *Reader) resolve(ctr *docker.Container) (string, error) {
+	base := r.base()
+	for _, rel := range candidatePaths(ctr.ID, ctr.CgroupParent) {
+		if fi, err := os.Stat(filepath.Join(base, rel)); err == nil && fi.IsDir() {
+			return rel, nil
+		}
+	}
+	return "", fmt.Errorf("cgroup for container %s not found under %s", ctr.ID, base)
+}
+
+// candidatePaths lists the cgroup paths, relative to the hierarchy root, where
+// Docker may have placed a container.
+func candidatePaths(id, parent string) []string {
+	if parent == "" {
+		return []string{
+			filepath.Join("system.slice", "docker-"+id+".scope"),
+			filepath.Join("docker", id),
+		}
+	}
+
+	// systemd driver: the parent is a slice name like "my-app.slice"
+	if strings.HasSuffix(parent, ".slice") && !strings.Contains(parent, "/") {
+		return []string{filepath.Join(expandSlice(parent), "docker-"+id+".scope")}
+	}
+
+	// cgroupfs driver: the parent is a plain path
+	return []string{filepath.Join(strings.TrimPrefix(parent, "/"), id)}
+}
+
+// expandSlice turns a systemd slice name into its path, since systemd nests
+// slices by their dash-separated prefixes: "a-b-c.slice" lives at
+// "a.slice/a-b.slice/a-b-c.slice". The root slice "-.slice" is the hierarchy
+// root itself.
+func expandSlice(slice string) string {
+	name := strings.TrimSuffix(slice, ".slice")
+	if name == "" || name == "-" {
+		return ""
+	}
+
+	parts := strings.Split(name, "-")
+	path := make([]string, 0, len(parts))
+	prefix := ""
+	for _, p := range parts {
+		if prefix != "" {
+			prefix += "-"
+		}
+		prefix += p
+		path = append(path, prefix+".slice")
+	}
+	return filepath.Join(path...)
+}
+
+// readUint reads a file holding a single unsigned integer.
+func readUint(dir, name string) (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return v, nil
+}
+
+// readKeyValues reads a flat "key value" file such as memory.stat or cpu.stat.
+// Lines that don't parse are skipped.
+func readKeyValues(dir, name string) (map[string]uint64, error) {
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[fields[0]] = v
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return values, nil
+}
+
+// optional swallows "file does not exist" so a controller that isn't enabled
+// for the cgroup leaves its fields at zero instead of failing the whole read.
+func optional(err error) error {
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+)
+
+// PressureLine is one line of a pressure file: the share of wall time some
+// (or all) tasks were stalled, averaged over 10s, 60s and 300s, and the
+// cumulative stall time.
+type PressureLine struct {
+	Avg10  float64 // percent
+	Avg60  float64 // percent
+	Avg300 float64 // percent
+	Total  uint64  // microseconds
+}
+
+// Pressure holds the "some" and "full" lines of one pressure file. Full is
+// nil when the kernel doesn't report it (cpu.pressure before Linux 5.13).
+type Pressure struct {
+	Some *PressureLine
+	Full *PressureLine
+}
+
+// PSI holds a cgroup's pressure stall information. A resource is nil when its
+// file can't be read, which is the case for all of them when the kernel was
+// built or booted without PSI.
+type PSI struct {
+	CPU    *Pressure
+	Memory *Pressure
+	IO     *Pressure
+}
+
+// Pressure reads cpu.pressure, memory.pressure and io.pressure from a
+// container's cgroup. PSI is a cgroup v2 interface; on v1 all resources are
+// nil.
+func (r *Reader) Pressure(ctr *docker.Container) (*PSI, error) {
+	dir, err := r.Path(ctr)
+	if err != nil {
+		return nil, err
+	}
+
+	psi := &PSI{}
+	if r.version != V2 {
+		return psi, nil
+	}
+	for name, dst := range map[string]**Pressure{
+		"cpu.pressure":    &psi.CPU,
+		"memory.pressure": &psi.Memory,
+		"io.pressure":     &psi.IO,
+	} {
+		p, err := readPressure(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		*dst = p
+	}
+	return psi, nil
+}
+
+// readPressure reads one pressure file. Returns nil without error when PSI is
+// unavailable: the file is missing (CONFIG_PSI=n) or reading it fails with
+// EOPNOTSUPP (booted with psi=0).
+func readPressure(dir, name string) (*Pressure, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.EOPNOTSUPP) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p, err := parsePressure(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return p, nil
+}
+
+// parsePressure parses pressure file contents:
+//
+//	some avg10=0.12 avg60=0.05 avg300=0.01 total=123456
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=7890
+func parsePressure(data string) (*Pressure, error) {
+	p := &Pressure{}
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		pl := &PressureLine{}
+		for _, kv := range fields[1:] {
+			key, val, ok := strings.Cut(kv, "=")
+			if !ok {
+				return nil, fmt.Errorf("malformed field %q", kv)
+			}
+			var err error
+			switch key {
+			case "avg10":
+				pl.Avg10, err = strconv.ParseFloat(val, 64)
+			case "avg60":
+				pl.Avg60, err = strconv.ParseFloat(val, 64)
+			case "avg300":
+				pl.Avg300, err = strconv.ParseFloat(val, 64)
+			case "total":
+				pl.Total, err = strconv.ParseUint(val, 10, 64)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", kv, err)
+			}
+		}
+
+		switch fields[0] {
+		case "some":
+			p.Some = pl
+		case "full":
+			p.Full = pl
+		}
+	}
+	if p.Some == nil {
+		return nil, fmt.Errorf("no \"some\" line")
+	}
+	return p, nil
+}
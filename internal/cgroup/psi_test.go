@@ -0,0 +1,55 @@
+package cgroup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+)
+
+func TestParsePressure(t *testing.T) {
+	p, err := parsePressure("some avg10=1.50 avg60=0.75 avg300=0.25 total=2500000\n")
+	require.NoError(t, err)
+	require.NotNil(t, p.Some)
+	assert.Equal(t, 1.5, p.Some.Avg10)
+	assert.Equal(t, 0.75, p.Some.Avg60)
+	assert.Equal(t, 0.25, p.Some.Avg300)
+	assert.Equal(t, uint64(2500000), p.Some.Total)
+	assert.Nil(t, p.Full, "cpu.pressure has no full line before Linux 5.13")
+
+	_, err = parsePressure("some avg10=x total=1\n")
+	assert.Error(t, err)
+	_, err = parsePressure("")
+	assert.Error(t, err)
+}
+
+func TestReader_Pressure(t *testing.T) {
+	r, err := NewReader(testV2Root)
+	require.NoError(t, err)
+
+	psi, err := r.Pressure(&docker.Container{ID: systemdID})
+	require.NoError(t, err)
+	require.NotNil(t, psi.CPU)
+	require.NotNil(t, psi.Memory)
+	require.NotNil(t, psi.IO)
+	assert.Equal(t, uint64(2500000), psi.CPU.Some.Total)
+	assert.Equal(t, 10.0, psi.Memory.Full.Avg10)
+	assert.Equal(t, uint64(500), psi.IO.Full.Total)
+}
+
+func TestReader_PressureUnavailable(t *testing.T) {
+	r, err := NewReader(testV2Root)
+	require.NoError(t, err)
+
+	// No pressure files, as on a kernel without CONFIG_PSI
+	psi, err := r.Pressure(&docker.Container{ID: cgroupfsID})
+	require.NoError(t, err)
+	assert.Nil(t, psi.CPU)
+	assert.Nil(t, psi.Memory)
+	assert.Nil(t, psi.IO)
+
+	_, err = r.Pressure(&docker.Container{ID: "gone"})
+	assert.Error(t, err)
+}
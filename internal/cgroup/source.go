@@ -0,0 +1,113 @@
+package cgroup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	containertypes "github.com/docker/docker/api/types/container"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+)
+
+// Source serves container stats from cgroup files. The Docker API is only used
+// for the container list (names, labels, state) and, once, for host memory.
+// Network counters are not available from cgroups and are left empty.
+//
+// The API pairs every sample with the previous one (precpu_stats); Source
+// does the same with the last sample it read for the container, so CPU usage
+// ratios work as they do with the API sources.
+type Source struct {
+	reader *Reader
+	lister docker.ContainerLister
+	host   *docker.HostResources
+
+	mu         sync.RWMutex
+	containers map[string]docker.Container
+	prev       map[string]cpuSample
+}
+
+// cpuSample is the CPU part of a previous read, replayed as precpu_stats.
+type cpuSample struct {
+	read time.Time
+	cpu  containertypes.CPUStats
+}
+
+// NewSource creates a cgroup-backed stats source. Containers are listed
+// through lister (the client itself or an Inventory).
+func NewSource(reader *Reader, lister docker.ContainerLister, client *docker.Client) *Source {
+	return newSource(reader, lister, client)
+}
+
+func newSource(reader *Reader, lister docker.ContainerLister, info docker.HostInfoGetter) *Source {
+	return &Source{
+		reader:     reader,
+		lister:     lister,
+		host:       docker.NewHostResources(info),
+		containers: make(map[string]docker.Container),
+		prev:       make(map[string]cpuSample),
+	}
+}
+
+// ListContainers lists containers and remembers them, so stats lookups by ID
+// can find the cgroup parent and identity.
+func (s *Source) ListContainers(ctx context.Context) ([]docker.Container, error) {
+	containers, err := s.lister.ListContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]docker.Container, len(containers))
+	for _, c := range containers {
+		known[c.ID] = c
+	}
+
+	s.mu.Lock()
+	s.containers = known
+	for id := range s.prev {
+		if _, ok := known[id]; !ok {
+			delete(s.prev, id)
+		}
+	}
+	s.mu.Unlock()
+
+	return containers, nil
+}
+
+// GetContainerStats reads the container's cgroup files.
+func (s *Source) GetContainerStats(ctx context.Context, id string) (*docker.Stats, error) {
+	s.mu.RLock()
+	ctr, ok := s.containers[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("container %s not in last listing", id)
+	}
+
+	statsJSON, err := s.reader.Read(&ctr)
+	if err != nil {
+		return nil, fmt.Errorf("getting stats for %s: %w", id, err)
+	}
+
+	// Unlimited containers report host memory as their limit, as the API
+	// does. v1 expresses "unlimited" as a huge value rather than 0, so any
+	// limit above host memory is clamped too. Until the daemon answers, the
+	// cgroup's limit is kept as read.
+	total, cpus := s.host.Get(ctx)
+	if limit := statsJSON.MemoryStats.Limit; total > 0 && (limit == 0 || limit > total) {
+		statsJSON.MemoryStats.Limit = total
+	}
+	if statsJSON.CPUStats.OnlineCPUs == 0 && cpus > 0 {
+		statsJSON.CPUStats.OnlineCPUs = uint32(cpus)
+	}
+
+	s.mu.Lock()
+	if prev, ok := s.prev[id]; ok {
+		statsJSON.PreRead = prev.read
+		statsJSON.PreCPUStats = prev.cpu
+	}
+	s.prev[id] = cpuSample{read: statsJSON.Read, cpu: statsJSON.CPUStats}
+	s.mu.Unlock()
+
+	return docker.ParseContainerStats(statsJSON, &ctr), nil
+}
@@ -0,0 +1,204 @@
+package cgroup
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+)
+
+// userHZ is the kernel's USER_HZ, the unit of cpuacct.stat. It is 100 on
+// every architecture Docker supports.
+const userHZ = 100
+
+// readV1 builds a stats response from the per-controller cgroup v1
+// hierarchies under root, filling the same fields the daemon fills on a v1
+// host. rel is the container's cgroup path inside each hierarchy.
+func readV1(root, rel string) (*types.StatsJSON, error) {
+	s := &types.StatsJSON{}
+	s.Read = time.Now()
+
+	// The memory hierarchy is where the container was resolved, so a failure
+	// here means the container is gone.
+	memDir := filepath.Join(root, "memory", rel)
+	usage, err := readUint(memDir, "memory.usage_in_bytes")
+	if err != nil {
+		return nil, fmt.Errorf("reading cgroup %s: %w", memDir, err)
+	}
+	s.MemoryStats.Usage = usage
+
+	if err := readV1Memory(memDir, &s.MemoryStats); err != nil {
+		return nil, err
+	}
+	if err := readV1CPU(root, rel, &s.CPUStats); err != nil {
+		return nil, err
+	}
+	if err := readV1Blkio(filepath.Join(root, "blkio", rel), &s.BlkioStats); err != nil {
+		return nil, err
+	}
+
+	pids, err := readUint(filepath.Join(root, "pids", rel), "pids.current")
+	if optional(err) != nil {
+		return nil, err
+	}
+	s.PidsStats.Current = pids
+
+	return s, nil
+}
+
+func readV1Memory(dir string, mem *containertypes.MemoryStats) error {
+	limit, err := readUint(dir, "memory.limit_in_bytes")
+	if optional(err) != nil {
+		return err
+	}
+	mem.Limit = limit
+
+	failcnt, err := readUint(dir, "memory.failcnt")
+	if optional(err) != nil {
+		return err
+	}
+	mem.Failcnt = failcnt
+
+	// memory.stat already carries the v1 keys parseMemoryStats understands
+	// (cache, rss, swap, total_inactive_file, ...).
+	stat, err := readKeyValues(dir, "memory.stat")
+	if optional(err) != nil {
+		return err
+	}
+	mem.Stats = stat
+
+	return nil
+}
+
+func readV1CPU(root, rel string, cpu *containertypes.CPUStats) error {
+	acct := filepath.Join(root, "cpuacct", rel)
+
+	total, err := readUint(acct, "cpuacct.usage")
+	if optional(err) != nil {
+		return err
+	}
+	cpu.CPUUsage.TotalUsage = total
+
+	percpu, err := readUintList(acct, "cpuacct.usage_percpu")
+	if optional(err) != nil {
+		return err
+	}
+	cpu.CPUUsage.PercpuUsage = percpu
+
+	// cpuacct.stat is in USER_HZ ticks; the API reports nanoseconds
+	stat, err := readKeyValues(acct, "cpuacct.stat")
+	if optional(err) != nil {
+		return err
+	}
+	cpu.CPUUsage.UsageInUsermode = stat["user"] * (1e9 / userHZ)
+	cpu.CPUUsage.UsageInKernelmode = stat["system"] * (1e9 / userHZ)
+
+	// Throttling lives in the cpu controller, in nanoseconds already
+	throttling, err := readKeyValues(filepath.Join(root, "cpu", rel), "cpu.stat")
+	if optional(err) != nil {
+		return err
+	}
+	cpu.ThrottlingData.Periods = throttling["nr_periods"]
+	cpu.ThrottlingData.ThrottledPeriods = throttling["nr_throttled"]
+	cpu.ThrottlingData.ThrottledTime = throttling["throttled_time"]
+
+	// online_cpus is the host's CPU count in the API, not the container's
+	// cpuset; it is left for the source to fill in.
+	return nil
+}
+
+// readV1Blkio reads the throttle-layer blkio files, which are populated for
+// every I/O scheduler, then the CFQ/BFQ scheduler files, which are missing or
+// empty under other schedulers.
+func readV1Blkio(dir string, bio *containertypes.BlkioStats) error {
+	for _, f := range []struct {
+		name string
+		dst  *[]containertypes.BlkioStatEntry
+	}{
+		{"blkio.throttle.io_service_bytes_recursive", &bio.IoServiceBytesRecursive},
+		{"blkio.throttle.io_serviced_recursive", &bio.IoServicedRecursive},
+		{"blkio.io_service_time_recursive", &bio.IoServiceTimeRecursive},
+		{"blkio.io_wait_time_recursive", &bio.IoWaitTimeRecursive},
+		{"blkio.io_merged_recursive", &bio.IoMergedRecursive},
+		{"blkio.io_queued_recursive", &bio.IoQueuedRecursive},
+		{"blkio.time_recursive", &bio.IoTimeRecursive},
+	} {
+		entries, err := readBlkioFile(dir, f.name)
+		if optional(err) != nil {
+			return err
+		}
+		*f.dst = entries
+	}
+	return nil
+}
+
+// readBlkioFile parses blkio lines like "8:0 Read 4096", or "8:0 4096" for
+// files without an op breakdown. The trailing "Total <n>" summary line has
+// no device and is skipped.
+func readBlkioFile(dir, name string) ([]containertypes.BlkioStatEntry, error) {
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []containertypes.BlkioStatEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		var op string
+		switch len(fields) {
+		case 2:
+			fields = []string{fields[0], "", fields[1]}
+		case 3:
+			op = fields[1]
+		default:
+			continue
+		}
+		majStr, minStr, ok := strings.Cut(fields[0], ":")
+		if !ok {
+			continue
+		}
+		major, err1 := strconv.ParseUint(majStr, 10, 64)
+		minor, err2 := strconv.ParseUint(minStr, 10, 64)
+		value, err3 := strconv.ParseUint(fields[2], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		entries = append(entries, containertypes.BlkioStatEntry{
+			Major: major,
+			Minor: minor,
+			Op:    op,
+			Value: value,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return entries, nil
+}
+
+// readUintList reads a file holding space-separated unsigned integers.
+func readUintList(dir, name string) ([]uint64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(string(data))
+	values := make([]uint64, 0, len(fields))
+	for _, f := range fields {
+		v, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
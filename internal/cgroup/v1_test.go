@@ -0,0 +1,108 @@
+package cgroup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+)
+
+const (
+	testV1Root = "../../testdata/cgroup/v1"
+
+	// Laid out as <controller>/docker/<id> (cgroupfs driver), all controllers
+	v1CgroupfsID = "cccc1111dddd2222eeee3333ffff4444aaaa5555bbbb6666cccc7777dddd8888"
+	// Laid out as <controller>/system.slice/docker-<id>.scope, memory and
+	// cpuacct only
+	v1SystemdID = "1234abcd1234abcd1234abcd1234abcd1234abcd1234abcd1234abcd1234abcd"
+)
+
+func TestNewReader_DetectsVersion(t *testing.T) {
+	r, err := NewReader(testV1Root)
+	require.NoError(t, err)
+	assert.Equal(t, V1, r.Version())
+
+	r, err = NewReader(testV2Root)
+	require.NoError(t, err)
+	assert.Equal(t, V2, r.Version())
+}
+
+func TestReadV1_CgroupfsLayout(t *testing.T) {
+	r, err := NewReader(testV1Root)
+	require.NoError(t, err)
+
+	statsJSON, err := r.Read(&docker.Container{ID: v1CgroupfsID})
+	require.NoError(t, err)
+
+	s := docker.ParseResourceStats(statsJSON)
+
+	// Memory: v1 keys
+	assert.Equal(t, uint64(209715200), s.MemoryUsage)
+	assert.Equal(t, uint64(41943040), s.MemoryCache)
+	assert.Equal(t, uint64(146800640), s.MemoryRSS)
+	assert.Equal(t, uint64(1048576), s.MemorySwap)
+	assert.Equal(t, uint64(3), s.MemoryFailcnt)
+	assert.Equal(t, uint64(209715200-10485760), s.MemoryWorkingSet)
+
+	// CPU: cpuacct.stat ticks converted to nanoseconds
+	assert.Equal(t, uint64(250000000000), s.CPUUsageTotal)
+	assert.Equal(t, uint64(200000000000), s.CPUUsageUser)
+	assert.Equal(t, uint64(50000000000), s.CPUUsageSystem)
+	assert.Equal(t, uint64(10), s.CPUThrottledPeriods)
+	assert.Equal(t, uint64(2000000000), s.CPUThrottledTime)
+	assert.Zero(t, s.OnlineCPUs, "the host's count, filled in by the source")
+	assert.Len(t, statsJSON.CPUStats.CPUUsage.PercpuUsage, 4)
+	assert.Len(t, s.CPUUsagePerCPU, 4)
+
+	// Block I/O: capitalized ops, Total lines ignored
+	require.Contains(t, s.BlockIO, "8:0")
+	assert.Equal(t, uint64(65536), s.BlockIO["8:0"].ReadBytes)
+	assert.Equal(t, uint64(131072), s.BlockIO["8:0"].WriteBytes)
+	assert.Equal(t, uint64(16), s.BlockIO["8:0"].ReadOps)
+	assert.Equal(t, uint64(32), s.BlockIO["8:0"].WriteOps)
+	assert.True(t, s.BlockIO["8:0"].HasDiscard, "Discard op line")
+
+	// CFQ files, where present
+	assert.True(t, s.BlockIO["8:0"].HasSchedStats)
+	assert.Equal(t, uint64(2000000), s.BlockIO["8:0"].ReadServiceTime)
+	assert.Equal(t, uint64(3), s.BlockIO["8:0"].WriteMerged)
+	assert.Equal(t, uint64(1), s.BlockIO["8:0"].Queued)
+	assert.Equal(t, uint64(1500), s.BlockIO["8:0"].IOTime)
+
+	assert.Equal(t, uint64(12), s.PIDsCurrent)
+}
+
+func TestReadV1_SystemdLayoutMissingControllers(t *testing.T) {
+	r, err := NewReader(testV1Root)
+	require.NoError(t, err)
+
+	statsJSON, err := r.Read(&docker.Container{ID: v1SystemdID})
+	require.NoError(t, err)
+
+	s := docker.ParseResourceStats(statsJSON)
+	assert.Equal(t, uint64(1048576), s.MemoryUsage)
+	assert.Equal(t, uint64(268435456), s.MemoryLimit)
+	assert.Equal(t, uint64(5000000), s.CPUUsageTotal)
+	assert.Equal(t, uint64(0), s.PIDsCurrent)
+	assert.Empty(t, s.BlockIO)
+}
+
+func TestSource_ClampsV1UnlimitedToHostMemory(t *testing.T) {
+	r, err := NewReader(testV1Root)
+	require.NoError(t, err)
+
+	lister := &fakeLister{containers: []docker.Container{{ID: v1CgroupfsID, Name: "app", State: "running"}}}
+	src := newSource(r, lister, &fakeInfo{memTotal: 16 << 30, ncpu: 8})
+
+	ctx := context.Background()
+	_, err = src.ListContainers(ctx)
+	require.NoError(t, err)
+
+	stats, err := src.GetContainerStats(ctx, v1CgroupfsID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(16<<30), stats.MemoryLimit)
+	assert.Equal(t, uint32(8), stats.OnlineCPUs, "host CPUs, as the API reports")
+}
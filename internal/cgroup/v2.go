@@ -0,0 +1,169 @@
+package cgroup
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+)
+
+// readV2 builds a stats response from a cgroup v2 directory, filling the same
+// fields the daemon fills on a v2 host.
+func readV2(dir string) (*types.StatsJSON, error) {
+	s := &types.StatsJSON{}
+	s.Read = time.Now()
+
+	// memory.current is always present while the cgroup exists, so a failure
+	// here means the container is gone.
+	usage, err := readUint(dir, "memory.current")
+	if err != nil {
+		return nil, fmt.Errorf("reading cgroup %s: %w", dir, err)
+	}
+	s.MemoryStats.Usage = usage
+
+	if err := readV2Memory(dir, &s.MemoryStats); err != nil {
+		return nil, err
+	}
+	if err := readV2CPU(dir, &s.CPUStats); err != nil {
+		return nil, err
+	}
+	if err := readV2IO(dir, &s.BlkioStats); err != nil {
+		return nil, err
+	}
+
+	pids, err := readUint(dir, "pids.current")
+	if optional(err) != nil {
+		return nil, err
+	}
+	s.PidsStats.Current = pids
+
+	return s, nil
+}
+
+func readV2Memory(dir string, mem *containertypes.MemoryStats) error {
+	data, err := os.ReadFile(filepath.Join(dir, "memory.max"))
+	if optional(err) != nil {
+		return err
+	}
+	if v := strings.TrimSpace(string(data)); v != "" && v != "max" {
+		if limit, err := strconv.ParseUint(v, 10, 64); err == nil {
+			mem.Limit = limit
+		}
+	}
+
+	stat, err := readKeyValues(dir, "memory.stat")
+	if optional(err) != nil {
+		return err
+	}
+	if stat == nil {
+		stat = make(map[string]uint64)
+	}
+
+	// The daemon doesn't put swap in the v2 stats map, but parseMemoryStats
+	// picks it up if it's there.
+	if swap, err := readUint(dir, "memory.swap.current"); err == nil {
+		stat["swap"] = swap
+	}
+	mem.Stats = stat
+
+	return nil
+}
+
+func readV2CPU(dir string, cpu *containertypes.CPUStats) error {
+	stat, err := readKeyValues(dir, "cpu.stat")
+	if optional(err) != nil {
+		return err
+	}
+
+	// cpu.stat reports microseconds; the API reports nanoseconds
+	cpu.CPUUsage.TotalUsage = stat["usage_usec"] * 1000
+	cpu.CPUUsage.UsageInUsermode = stat["user_usec"] * 1000
+	cpu.CPUUsage.UsageInKernelmode = stat["system_usec"] * 1000
+	cpu.ThrottlingData.Periods = stat["nr_periods"]
+	cpu.ThrottlingData.ThrottledPeriods = stat["nr_throttled"]
+	cpu.ThrottlingData.ThrottledTime = stat["throttled_usec"] * 1000
+
+	data, err := os.ReadFile(filepath.Join(dir, "cpuset.cpus.effective"))
+	if optional(err) != nil {
+		return err
+	}
+	cpu.OnlineCPUs = docker.CountCPUs(string(data))
+
+	return nil
+}
+
+// readV2IO parses io.stat lines like
+//
+//	8:0 rbytes=1024 wbytes=2048 rios=4 wios=8 dbytes=0 dios=0
+//
+// into the v1-shaped blkio entries the API uses, with lowercase ops as the
+// daemon emits on v2. Discards, which the daemon drops, are kept under a
+// "discard" op.
+func readV2IO(dir string, bio *containertypes.BlkioStats) error {
+	f, err := os.Open(filepath.Join(dir, "io.stat"))
+	if err != nil {
+		return optional(err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		majStr, minStr, ok := strings.Cut(fields[0], ":")
+		if !ok {
+			continue
+		}
+		major, err1 := strconv.ParseUint(majStr, 10, 64)
+		minor, err2 := strconv.ParseUint(minStr, 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+
+		for _, kv := range fields[1:] {
+			key, val, ok := strings.Cut(kv, "=")
+			if !ok {
+				continue
+			}
+			v, err := strconv.ParseUint(val, 10, 64)
+			if err != nil {
+				continue
+			}
+			entry := containertypes.BlkioStatEntry{Major: major, Minor: minor, Value: v}
+			switch key {
+			case "rbytes":
+				entry.Op = "read"
+				bio.IoServiceBytesRecursive = append(bio.IoServiceBytesRecursive, entry)
+			case "wbytes":
+				entry.Op = "write"
+				bio.IoServiceBytesRecursive = append(bio.IoServiceBytesRecursive, entry)
+			case "rios":
+				entry.Op = "read"
+				bio.IoServicedRecursive = append(bio.IoServicedRecursive, entry)
+			case "wios":
+				entry.Op = "write"
+				bio.IoServicedRecursive = append(bio.IoServicedRecursive, entry)
+			case "dbytes":
+				entry.Op = "discard"
+				bio.IoServiceBytesRecursive = append(bio.IoServiceBytesRecursive, entry)
+			case "dios":
+				entry.Op = "discard"
+				bio.IoServicedRecursive = append(bio.IoServicedRecursive, entry)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading io.stat: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package cgroup
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+)
+
+const (
+	testV2Root = "../../testdata/cgroup/v2"
+
+	// Laid out as system.slice/docker-<id>.scope (systemd driver)
+	systemdID = "aaaa1111bbbb2222cccc3333dddd4444eeee5555ffff6666aaaa7777bbbb8888"
+	// Laid out as docker/<id> (cgroupfs driver)
+	cgroupfsID = "9999eeee8888dddd7777cccc6666bbbb5555aaaa444433332222111100009999"
+)
+
+type fakeLister struct {
+	containers []docker.Container
+}
+
+func (f *fakeLister) ListContainers(_ context.Context) ([]docker.Container, error) {
+	return f.containers, nil
+}
+
+type fakeInfo struct {
+	memTotal int64
+	ncpu     int
+	err      error
+	calls    int
+}
+
+func (f *fakeInfo) GetHostInfo(_ context.Context) (*docker.HostInfo, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &docker.HostInfo{MemTotal: f.memTotal, NCPU: f.ncpu}, nil
+}
+
+func TestNewReader_RejectsMissingHierarchy(t *testing.T) {
+	_, err := NewReader(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestReadV2_SystemdLayout(t *testing.T) {
+	r, err := NewReader(testV2Root)
+	require.NoError(t, err)
+
+	statsJSON, err := r.Read(&docker.Container{ID: systemdID})
+	require.NoError(t, err)
+
+	s := docker.ParseResourceStats(statsJSON)
+
+	// Memory
+	assert.Equal(t, uint64(104857600), s.MemoryUsage)
+	assert.Equal(t, uint64(536870912), s.MemoryLimit)
+	assert.Equal(t, uint64(20971520), s.MemoryCache) // file
+	assert.Equal(t, uint64(73400320), s.MemoryRSS)   // anon
+	assert.Equal(t, uint64(104857600-5242880), s.MemoryWorkingSet)
+
+	// CPU: microseconds converted to nanoseconds
+	assert.Equal(t, uint64(500000000000), s.CPUUsageTotal)
+	assert.Equal(t, uint64(400000000000), s.CPUUsageUser)
+	assert.Equal(t, uint64(100000000000), s.CPUUsageSystem)
+	assert.Equal(t, uint64(25), s.CPUThrottledPeriods)
+	assert.Equal(t, uint64(1500000000), s.CPUThrottledTime)
+	assert.Equal(t, uint32(4), s.OnlineCPUs)
+
+	// Block I/O
+	require.Contains(t, s.BlockIO, "8:0")
+	assert.Equal(t, uint64(4096), s.BlockIO["8:0"].ReadBytes)
+	assert.Equal(t, uint64(8192), s.BlockIO["8:0"].WriteBytes)
+	assert.Equal(t, uint64(2), s.BlockIO["8:0"].ReadOps)
+	assert.Equal(t, uint64(4), s.BlockIO["8:0"].WriteOps)
+	require.Contains(t, s.BlockIO, "259:0")
+	assert.Equal(t, uint64(1048576), s.BlockIO["259:0"].ReadBytes)
+	assert.True(t, s.BlockIO["259:0"].HasDiscard)
+	assert.Equal(t, uint64(512), s.BlockIO["259:0"].DiscardBytes)
+	assert.Equal(t, uint64(1), s.BlockIO["259:0"].DiscardOps)
+	assert.False(t, s.BlockIO["259:0"].HasSchedStats)
+
+	// PIDs
+	assert.Equal(t, uint64(25), s.PIDsCurrent)
+
+	// No network from cgroups
+	assert.Empty(t, s.Networks)
+}
+
+func TestReadV2_CgroupfsLayoutMissingControllers(t *testing.T) {
+	r, err := NewReader(testV2Root)
+	require.NoError(t, err)
+
+	// This cgroup has no io.stat or cpuset file; those fields stay zero
+	statsJSON, err := r.Read(&docker.Container{ID: cgroupfsID})
+	require.NoError(t, err)
+
+	s := docker.ParseResourceStats(statsJSON)
+	assert.Equal(t, uint64(52428800), s.MemoryUsage)
+	assert.Equal(t, uint64(0), s.MemoryLimit, "memory.max=max means unlimited")
+	assert.Equal(t, uint64(1000000), s.CPUUsageTotal)
+	assert.Equal(t, uint64(3), s.PIDsCurrent)
+	assert.Empty(t, s.BlockIO)
+}
+
+func TestRead_UnknownContainer(t *testing.T) {
+	r, err := NewReader(testV2Root)
+	require.NoError(t, err)
+
+	_, err = r.Read(&docker.Container{ID: "doesnotexist"})
+	assert.Error(t, err)
+}
+
+func TestCandidatePaths(t *testing.T) {
+	assert.Equal(t,
+		[]string{"system.slice/docker-abc.scope", "docker/abc"},
+		candidatePaths("abc", ""))
+	assert.Equal(t,
+		[]string{"my.slice/my-app.slice/docker-abc.scope"},
+		candidatePaths("abc", "my-app.slice"))
+	assert.Equal(t,
+		[]string{"custom/parent/abc"},
+		candidatePaths("abc", "/custom/parent"))
+}
+
+func TestSource_FillsIdentityAndHostMemory(t *testing.T) {
+	r, err := NewReader(testV2Root)
+	require.NoError(t, err)
+
+	lister := &fakeLister{containers: []docker.Container{
+		{ID: cgroupfsID, Name: "db", Image: "postgres:16", State: "running", Labels: map[string]string{docker.LabelComposeService: "db"}},
+	}}
+	info := &fakeInfo{memTotal: 8 << 30}
+	src := newSource(r, lister, info)
+
+	ctx := context.Background()
+	_, err = src.ListContainers(ctx)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		stats, err := src.GetContainerStats(ctx, cgroupfsID)
+		require.NoError(t, err)
+		assert.Equal(t, "db", stats.Name)
+		assert.Equal(t, "postgres:16", stats.Image)
+		assert.Equal(t, "running", stats.Status)
+		assert.Equal(t, uint64(8<<30), stats.MemoryLimit, "unlimited containers report host memory")
+		// The first read has no previous sample to compute usage against
+		assert.Equal(t, i > 0, stats.HasCPUUsageRatio)
+		assert.Zero(t, stats.CPUUsageRatio, "testdata counters don't move")
+	}
+	assert.Equal(t, 1, info.calls, "host memory should be looked up once")
+
+	_, err = src.GetContainerStats(ctx, "unlisted")
+	assert.Error(t, err)
+}
+
+func TestSource_KeepsLimitWithoutHostMemory(t *testing.T) {
+	r, err := NewReader(testV2Root)
+	require.NoError(t, err)
+
+	lister := &fakeLister{containers: []docker.Container{{ID: systemdID, Name: "web", State: "running"}}}
+	src := newSource(r, lister, &fakeInfo{err: fmt.Errorf("connection refused")})
+
+	ctx := context.Background()
+	_, err = src.ListContainers(ctx)
+	require.NoError(t, err)
+
+	stats, err := src.GetContainerStats(ctx, systemdID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(536870912), stats.MemoryLimit, "cgroup limit kept while the daemon doesn't answer")
+	assert.Equal(t, uint32(4), stats.OnlineCPUs)
+}
@@ -2,6 +2,8 @@ package collector
 
 import (
 	"context"
+	"net"
+	"strconv"
 	"sync"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 
 	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
 	"github.com/fabienpiette/docker-stats-exporter/internal/metrics"
+	"github.com/fabienpiette/docker-stats-exporter/internal/procfs"
 	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
 )
 
@@ -24,24 +27,55 @@ type ContainerCollector struct {
 	client        DockerClient
 	filter        *docker.Filter
 	cache         *StatsCache
+	devices       *docker.DeviceNames
+	host          *docker.HostResources
+	proc          *procfs.Reader
 	timeout       time.Duration
 	maxConcurrent int
+	perCPU        bool
+	memoryStat    bool
+	memoryKeys    map[string]bool // nil exports every key
 
 	scrapeErrors int64
 	mu           sync.Mutex
 }
 
-// NewContainerCollector creates a new container metrics collector.
-func NewContainerCollector(client DockerClient, filter *docker.Filter, cache *StatsCache, cfg *config.Config) *ContainerCollector {
+// NewContainerCollector creates a new container metrics collector. devices
+// resolves block device names for the device_name label; with nil, the label
+// repeats the device number. host supplies host memory and CPUs for the
+// memory ratios and host shares; with nil, the memory ratios fall back to the
+// inspected limits and host shares are left out. proc reads container routing
+// tables to tie interfaces to Docker networks; with nil, only the endpoint
+// order is used.
+func NewContainerCollector(client DockerClient, filter *docker.Filter, cache *StatsCache, devices *docker.DeviceNames, host *docker.HostResources, proc *procfs.Reader, cfg *config.Config) *ContainerCollector {
 	return &ContainerCollector{
 		client:        client,
 		filter:        filter,
 		cache:         cache,
+		devices:       devices,
+		host:          host,
+		proc:          proc,
 		timeout:       cfg.Collection.Timeout,
 		maxConcurrent: cfg.Performance.MaxConcurrent,
+		perCPU:        cfg.Collection.Stats.PerCPU,
+		memoryStat:    cfg.Collection.Stats.MemoryStat.Enabled,
+		memoryKeys:    memoryStatAllowlist(cfg.Collection.Stats.MemoryStat.Keys),
 	}
 }
 
+// memoryStatAllowlist builds the set of exported memory.stat keys. Keys given
+// by their cgroup v1 name are accepted too.
+func memoryStatAllowlist(keys []string) map[string]bool {
+	if len(keys) == 0 {
+		return nil
+	}
+	allow := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		allow[docker.MemoryStatName(k)] = true
+	}
+	return allow
+}
+
 // Describe sends all metric descriptors.
 func (c *ContainerCollector) Describe(ch chan<- *prometheus.Desc) {
 	for _, d := range metrics.AllContainerDescs() {
@@ -116,6 +150,7 @@ func (c *ContainerCollector) Collect(ch chan<- prometheus.Metric) {
 
 	// 4. Emit metrics for each container
 	now := time.Now()
+	hostMem, hostCPUs := c.host.Get(ctx)
 	for _, r := range results {
 		if r.err != nil {
 			log.WithError(r.err).WithField("container", r.container.Name).Warn("Failed to get container stats, skipping")
@@ -126,14 +161,23 @@ func (c *ContainerCollector) Collect(ch chan<- prometheus.Metric) {
 		labels := docker.ExtractLabels(&r.container)
 		lv := labels.Values()
 
-		// Always emit state metrics for all containers
+		// Always emit state and limit metrics for all containers
 		c.emitStateMetrics(ch, &r.container, lv, now)
+		c.emitLimitMetrics(ch, &r.container, lv)
 
 		// Only emit resource metrics for running containers with stats
 		if r.stats != nil {
 			c.emitMemoryMetrics(ch, r.stats, lv)
-			c.emitCPUMetrics(ch, r.stats, lv)
-			c.emitNetworkMetrics(ch, r.stats, lv)
+			c.emitMemoryRatioMetrics(ch, &r.container, r.stats, hostMem, lv)
+			c.emitHostShareMetrics(ch, r.stats, hostMem, hostCPUs, lv)
+			if c.memoryStat {
+				c.emitMemoryStatMetrics(ch, r.stats, lv)
+			}
+			c.emitCPUMetrics(ch, &r.container, r.stats, lv)
+			if c.perCPU {
+				c.emitPerCPUMetrics(ch, &r.container, r.stats, lv)
+			}
+			c.emitNetworkMetrics(ch, &r.container, r.stats, lv)
 			c.emitBlockIOMetrics(ch, r.stats, lv)
 			c.emitPIDsMetrics(ch, r.stats, lv)
 		}
@@ -155,15 +199,102 @@ func (c *ContainerCollector) emitMemoryMetrics(ch chan<- prometheus.Metric, s *d
 	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.MemoryFailcnt, prometheus.GaugeValue, float64(s.MemoryFailcnt), lv...))
 }
 
-func (c *ContainerCollector) emitCPUMetrics(ch chan<- prometheus.Metric, s *docker.Stats, lv []string) {
+// emitMemoryRatioMetrics reports usage against the effective limit. The API
+// reports host memory as the limit of unlimited containers, so a limit at or
+// above host memory means there is none. Without host memory, the inspected
+// limit decides.
+func (c *ContainerCollector) emitMemoryRatioMetrics(ch chan<- prometheus.Metric, ctr *docker.Container, s *docker.Stats, hostMem uint64, lv []string) {
+	limit := s.MemoryLimit
+	var unlimited bool
+	if hostMem > 0 {
+		unlimited = limit == 0 || limit >= hostMem
+		if unlimited {
+			limit = hostMem
+		}
+	} else {
+		unlimited = ctr.Limits.Memory == 0
+	}
+	if limit == 0 {
+		return
+	}
+
+	var flag float64
+	if unlimited {
+		flag = 1
+	}
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.MemoryUsageRatio, prometheus.GaugeValue, float64(s.MemoryUsage)/float64(limit), lv...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.MemoryWorkingSetRatio, prometheus.GaugeValue, float64(s.MemoryWorkingSet)/float64(limit), lv...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.MemoryUnlimited, prometheus.GaugeValue, flag, lv...))
+}
+
+// emitHostShareMetrics reports the container's share of the host: CPU usage
+// over every host CPU, and working set over host memory.
+func (c *ContainerCollector) emitHostShareMetrics(ch chan<- prometheus.Metric, s *docker.Stats, hostMem uint64, hostCPUs int, lv []string) {
+	if s.HasCPUUsageRatio && hostCPUs > 0 {
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.CPUHostShare, prometheus.GaugeValue, s.CPUUsageRatio/float64(hostCPUs), lv...))
+	}
+	if hostMem > 0 {
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.MemoryHostShare, prometheus.GaugeValue, float64(s.MemoryWorkingSet)/float64(hostMem), lv...))
+	}
+}
+
+func (c *ContainerCollector) emitCPUMetrics(ch chan<- prometheus.Metric, ctr *docker.Container, s *docker.Stats, lv []string) {
 	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.CPUUsageTotal, prometheus.CounterValue, float64(s.CPUUsageTotal)*metrics.NanosecondsToSeconds, lv...))
 	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.CPUUsageSystem, prometheus.CounterValue, float64(s.CPUUsageSystem)*metrics.NanosecondsToSeconds, lv...))
 	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.CPUUsageUser, prometheus.CounterValue, float64(s.CPUUsageUser)*metrics.NanosecondsToSeconds, lv...))
 	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.CPUThrottledPeriods, prometheus.CounterValue, float64(s.CPUThrottledPeriods), lv...))
 	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.CPUThrottledTime, prometheus.CounterValue, float64(s.CPUThrottledTime)*metrics.NanosecondsToSeconds, lv...))
+
+	if s.HasCPUUsageRatio {
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.CPUUsageRatio, prometheus.GaugeValue, s.CPUUsageRatio, lv...))
+		if limit := ctr.Limits.CPULimitCores(); limit > 0 {
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.CPULimitUsageRatio, prometheus.GaugeValue, s.CPUUsageRatio/limit, lv...))
+		}
+	}
 }
 
-func (c *ContainerCollector) emitNetworkMetrics(ch chan<- prometheus.Metric, s *docker.Stats, lv []string) {
+func (c *ContainerCollector) emitMemoryStatMetrics(ch chan<- prometheus.Metric, s *docker.Stats, lv []string) {
+	for stat, v := range docker.NormalizeMemoryStat(s.MemoryStat) {
+		if c.memoryKeys != nil && !c.memoryKeys[stat] {
+			continue
+		}
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.MemoryStat, prometheus.GaugeValue, float64(v), append(lv, stat)...))
+	}
+}
+
+// emitPerCPUMetrics breaks CPU usage down per core. cgroup v2 has no per-CPU
+// accounting, so there the breakdown is only known for containers pinned to a
+// single CPU, which gets all of the usage. CPUs the container never ran on are
+// skipped.
+func (c *ContainerCollector) emitPerCPUMetrics(ch chan<- prometheus.Metric, ctr *docker.Container, s *docker.Stats, lv []string) {
+	if len(s.CPUUsagePerCPU) == 0 {
+		if cpu, ok := ctr.Limits.PinnedCPU(); ok {
+			clv := append(lv, strconv.Itoa(cpu))
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.CPUUsagePerCPU, prometheus.CounterValue, float64(s.CPUUsageTotal)*metrics.NanosecondsToSeconds, clv...))
+		}
+		return
+	}
+
+	for cpu, usage := range s.CPUUsagePerCPU {
+		if usage == 0 {
+			continue
+		}
+		clv := append(lv, strconv.Itoa(cpu))
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.CPUUsagePerCPU, prometheus.CounterValue, float64(usage)*metrics.NanosecondsToSeconds, clv...))
+	}
+}
+
+func (c *ContainerCollector) emitNetworkMetrics(ch chan<- prometheus.Metric, ctr *docker.Container, s *docker.Stats, lv []string) {
+	ifaces := make([]string, 0, len(s.Networks))
+	for iface := range s.Networks {
+		ifaces = append(ifaces, iface)
+	}
+	macs, subnets := c.interfaceAddresses(ctr)
+	for iface, ep := range docker.InterfaceEndpoints(ctr, ifaces, macs, subnets) {
+		ilv := append(lv, iface, ep.Network, ep.Driver, ep.IPAddress)
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.NetworkInterfaceInfo, prometheus.GaugeValue, 1, ilv...))
+	}
+
 	for iface, net := range s.Networks {
 		nlv := append(lv, iface)
 		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.NetworkRxBytes, prometheus.CounterValue, float64(net.RxBytes), nlv...))
@@ -177,13 +308,47 @@ func (c *ContainerCollector) emitNetworkMetrics(ch chan<- prometheus.Metric, s *
 	}
 }
 
+// interfaceAddresses reads the container's interface MAC addresses and
+// routing tables when it is attached to several networks; with one, its
+// interface is known without them.
+func (c *ContainerCollector) interfaceAddresses(ctr *docker.Container) (map[string]string, map[string][]*net.IPNet) {
+	if c.proc == nil || ctr.Pid == 0 || len(ctr.Endpoints) < 2 {
+		return nil, nil
+	}
+	macs, err := c.proc.InterfaceMACs(ctr.Pid)
+	if err != nil {
+		log.WithError(err).WithField("container", ctr.Name).Debug("Failed to read interface MAC addresses")
+	}
+	subnets, err := c.proc.InterfaceSubnets(ctr.Pid)
+	if err != nil {
+		log.WithError(err).WithField("container", ctr.Name).Debug("Failed to read routing table")
+	}
+	return macs, subnets
+}
+
 func (c *ContainerCollector) emitBlockIOMetrics(ch chan<- prometheus.Metric, s *docker.Stats, lv []string) {
 	for device, bio := range s.BlockIO {
-		dlv := append(lv, device)
+		dlv := append(lv, device, c.devices.Name(device))
 		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSReadBytes, prometheus.CounterValue, float64(bio.ReadBytes), dlv...))
 		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSWriteBytes, prometheus.CounterValue, float64(bio.WriteBytes), dlv...))
 		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSReadOps, prometheus.CounterValue, float64(bio.ReadOps), dlv...))
 		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSWriteOps, prometheus.CounterValue, float64(bio.WriteOps), dlv...))
+
+		// Counters a source doesn't report are left out rather than sent as 0
+		if bio.HasDiscard {
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSDiscardBytes, prometheus.CounterValue, float64(bio.DiscardBytes), dlv...))
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSDiscardOps, prometheus.CounterValue, float64(bio.DiscardOps), dlv...))
+		}
+		if bio.HasSchedStats {
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSReadTime, prometheus.CounterValue, float64(bio.ReadServiceTime)*metrics.NanosecondsToSeconds, dlv...))
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSWriteTime, prometheus.CounterValue, float64(bio.WriteServiceTime)*metrics.NanosecondsToSeconds, dlv...))
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSReadWaitTime, prometheus.CounterValue, float64(bio.ReadWaitTime)*metrics.NanosecondsToSeconds, dlv...))
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSWriteWaitTime, prometheus.CounterValue, float64(bio.WriteWaitTime)*metrics.NanosecondsToSeconds, dlv...))
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSReadsMerged, prometheus.CounterValue, float64(bio.ReadMerged), dlv...))
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSWritesMerged, prometheus.CounterValue, float64(bio.WriteMerged), dlv...))
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSIOCurrent, prometheus.GaugeValue, float64(bio.Queued), dlv...))
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSIOTime, prometheus.CounterValue, float64(bio.IOTime)/1000, dlv...))
+		}
 	}
 }
 
@@ -210,6 +375,33 @@ func (c *ContainerCollector) emitStateMetrics(ch chan<- prometheus.Metric, ctr *
 	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ContainerHealthStatus, prometheus.GaugeValue, metrics.HealthStatusToFloat(ctr.Health), lv...))
 	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ContainerRestartCount, prometheus.GaugeValue, float64(ctr.RestartCount), lv...))
 	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ContainerExitCode, prometheus.GaugeValue, float64(ctr.ExitCode), lv...))
+
+	var oomKilled float64
+	if ctr.OOMKilled {
+		oomKilled = 1
+	}
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ContainerOOMKilled, prometheus.GaugeValue, oomKilled, lv...))
+}
+
+func (c *ContainerCollector) emitLimitMetrics(ch chan<- prometheus.Metric, ctr *docker.Container, lv []string) {
+	// No restart policy means inspect failed; reporting zeros would read as
+	// "unlimited".
+	l := ctr.Limits
+	if l.RestartPolicy == "" {
+		return
+	}
+
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SpecCPULimit, prometheus.GaugeValue, l.CPULimitCores(), lv...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SpecCPUQuota, prometheus.GaugeValue, float64(l.CPUQuota), lv...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SpecCPUPeriod, prometheus.GaugeValue, float64(l.CPUPeriod), lv...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SpecCPUShares, prometheus.GaugeValue, float64(l.CPUShares), lv...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SpecCpusetCPUs, prometheus.GaugeValue, float64(docker.CountCPUs(l.CpusetCpus)), lv...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SpecMemoryLimit, prometheus.GaugeValue, float64(l.Memory), lv...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SpecMemoryReservation, prometheus.GaugeValue, float64(l.MemoryReservation), lv...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SpecMemorySwapLimit, prometheus.GaugeValue, float64(l.MemorySwap), lv...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SpecPIDsLimit, prometheus.GaugeValue, float64(l.PidsLimit), lv...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SpecRestartPolicy, prometheus.GaugeValue, 1, append(lv, l.RestartPolicy)...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SpecRestartMaxRetries, prometheus.GaugeValue, float64(l.RestartMaxRetries), lv...))
 }
 
 func (c *ContainerCollector) emitSelfMetrics(ch chan<- prometheus.Metric, start time.Time, errors int64) {
@@ -3,6 +3,8 @@ package collector
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -13,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/internal/procfs"
 	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
 )
 
@@ -122,7 +125,7 @@ func TestCollect_RunningContainer(t *testing.T) {
 	}
 
 	cache := NewStatsCache(30*time.Second, false)
-	collector := NewContainerCollector(mock, newTestFilter(), cache, newTestConfig())
+	collector := NewContainerCollector(mock, newTestFilter(), cache, nil, nil, nil, newTestConfig())
 	metrics := collectMetrics(collector)
 
 	// Should emit memory, CPU, network, block I/O, PIDs, and state metrics
@@ -163,7 +166,7 @@ func TestCollect_StoppedContainer(t *testing.T) {
 	}
 
 	cache := NewStatsCache(30*time.Second, false)
-	collector := NewContainerCollector(mock, newTestFilter(), cache, newTestConfig())
+	collector := NewContainerCollector(mock, newTestFilter(), cache, nil, nil, nil, newTestConfig())
 	metrics := collectMetrics(collector)
 
 	// Stopped containers emit state metrics but no resource metrics
@@ -183,13 +186,369 @@ func TestCollect_StoppedContainer(t *testing.T) {
 	assert.Empty(t, cpuTotal, "stopped containers should not emit CPU metrics")
 }
 
+func TestCollect_LimitMetrics(t *testing.T) {
+	mock := &mockDockerClient{
+		containers: []docker.Container{
+			{
+				ID:    "limited1aabbccddeeff",
+				Name:  "api",
+				Image: "app:1",
+				State: "exited",
+				Limits: docker.Limits{
+					NanoCPUs:      2_000_000_000,
+					Memory:        1 << 30,
+					MemorySwap:    -1,
+					PidsLimit:     200,
+					RestartPolicy: "unless-stopped",
+				},
+			},
+			{
+				// Inspect failed: no limits known
+				ID:    "noinspect1aabbccddee",
+				Name:  "mystery",
+				Image: "app:1",
+				State: "exited",
+			},
+		},
+	}
+
+	cache := NewStatsCache(30*time.Second, false)
+	collector := NewContainerCollector(mock, newTestFilter(), cache, nil, nil, nil, newTestConfig())
+	collected := collectMetrics(collector)
+
+	cpuLimit := findMetric(collected, "container_spec_cpu_limit_cores")
+	require.Len(t, cpuLimit, 1, "no limit metrics without inspect data")
+	assert.Equal(t, 2.0, gaugeValue(t, cpuLimit[0]))
+
+	memLimit := findMetric(collected, "container_spec_memory_limit_bytes")
+	require.Len(t, memLimit, 1)
+	assert.Equal(t, float64(1<<30), gaugeValue(t, memLimit[0]))
+
+	swap := findMetric(collected, "container_spec_memory_swap_limit_bytes")
+	require.Len(t, swap, 1)
+	assert.Equal(t, float64(-1), gaugeValue(t, swap[0]))
+
+	policy := findMetric(collected, "container_spec_restart_policy")
+	require.Len(t, policy, 1)
+	assert.Equal(t, "unless-stopped", metricLabels(t, policy[0])["policy"])
+}
+
+func TestCollect_BlockDeviceNames(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Symlink("../../devices/virtual/block/dm-3", filepath.Join(root, "253:3")))
+
+	mock := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "io1aabbccddeeff001122", Name: "db", Image: "postgres:16", State: "running"},
+		},
+		stats: map[string]*docker.Stats{
+			"io1aabbccddeeff001122": {BlockIO: map[string]docker.BlockIOStats{
+				"253:3": {ReadBytes: 4096},
+				"8:16":  {ReadBytes: 8192},
+			}},
+		},
+	}
+
+	devices := docker.NewDeviceNames(root)
+	collector := NewContainerCollector(mock, newTestFilter(), NewStatsCache(0, false), devices, nil, nil, newTestConfig())
+
+	names := map[string]string{}
+	for _, m := range findMetric(collectMetrics(collector), "container_fs_reads_bytes_total") {
+		l := metricLabels(t, m)
+		names[l["device"]] = l["device_name"]
+	}
+	assert.Equal(t, map[string]string{"253:3": "dm-3", "8:16": "8:16"}, names)
+}
+
+func TestCollect_ExtendedBlockIO(t *testing.T) {
+	mock := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "io1aabbccddeeff001122", Name: "db", Image: "postgres:16", State: "running"},
+		},
+		stats: map[string]*docker.Stats{
+			"io1aabbccddeeff001122": {BlockIO: map[string]docker.BlockIOStats{
+				// cgroup v1 with CFQ
+				"8:0": {ReadServiceTime: 1_500_000_000, Queued: 3, IOTime: 4200, HasSchedStats: true},
+				// cgroup v2
+				"259:0": {DiscardBytes: 512, DiscardOps: 1, HasDiscard: true},
+			}},
+		},
+	}
+
+	collector := NewContainerCollector(mock, newTestFilter(), NewStatsCache(0, false), nil, nil, nil, newTestConfig())
+	collected := collectMetrics(collector)
+
+	readTime := findMetric(collected, "container_fs_read_seconds_total")
+	require.Len(t, readTime, 1, "only devices with scheduler stats")
+	assert.Equal(t, "8:0", metricLabels(t, readTime[0])["device"])
+	assert.Equal(t, 1.5, counterValue(t, readTime[0]))
+
+	ioTime := findMetric(collected, "container_fs_io_time_seconds_total")
+	require.Len(t, ioTime, 1)
+	assert.Equal(t, 4.2, counterValue(t, ioTime[0]))
+
+	queued := findMetric(collected, "container_fs_io_current")
+	require.Len(t, queued, 1)
+	assert.Equal(t, 3.0, gaugeValue(t, queued[0]))
+
+	discards := findMetric(collected, "container_fs_discards_bytes_total")
+	require.Len(t, discards, 1, "only devices reporting discards")
+	assert.Equal(t, "259:0", metricLabels(t, discards[0])["device"])
+}
+
+func TestCollect_MemoryStat(t *testing.T) {
+	mock := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "v1aabbccddeeff001122", Name: "v1", Image: "app:1", State: "running"},
+			{ID: "v2aabbccddeeff001122", Name: "v2", Image: "app:1", State: "running"},
+		},
+		stats: map[string]*docker.Stats{
+			"v1aabbccddeeff001122": {MemoryStat: map[string]uint64{"cache": 100, "rss": 200, "shmem": 5, "total_cache": 100, "pgpgin": 7}},
+			"v2aabbccddeeff001122": {MemoryStat: map[string]uint64{"file": 300, "anon": 400, "kernel_stack": 16384, "sock": 0}},
+		},
+	}
+
+	collect := func(cfg *config.Config) map[string]float64 {
+		collector := NewContainerCollector(mock, newTestFilter(), NewStatsCache(0, false), nil, nil, nil, cfg)
+		got := map[string]float64{}
+		for _, m := range findMetric(collectMetrics(collector), "container_memory_stat") {
+			l := metricLabels(t, m)
+			got[l["container_name"]+"/"+l["stat"]] = gaugeValue(t, m)
+		}
+		return got
+	}
+
+	cfg := newTestConfig()
+	assert.Empty(t, collect(cfg), "opt-in")
+
+	// No allowlist: every key, under v2 names
+	cfg.Collection.Stats.MemoryStat.Enabled = true
+	assert.Equal(t, map[string]float64{
+		"v1/file": 100, "v1/anon": 200, "v1/shmem": 5, "v1/pgpgin": 7,
+		"v2/file": 300, "v2/anon": 400, "v2/kernel_stack": 16384, "v2/sock": 0,
+	}, collect(cfg))
+
+	// Allowlist entries may use either name
+	cfg.Collection.Stats.MemoryStat.Keys = []string{"cache", "kernel_stack"}
+	assert.Equal(t, map[string]float64{
+		"v1/file": 100,
+		"v2/file": 300, "v2/kernel_stack": 16384,
+	}, collect(cfg))
+}
+
+func TestCollect_PerCPU(t *testing.T) {
+	mock := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "v1aabbccddeeff001122", Name: "v1", Image: "app:1", State: "running"},
+			{ID: "pinnedaabbccddeeff00", Name: "pinned", Image: "app:1", State: "running", Limits: docker.Limits{CpusetCpus: "2", RestartPolicy: "no"}},
+			{ID: "spreadaabbccddeeff00", Name: "spread", Image: "app:1", State: "running", Limits: docker.Limits{CpusetCpus: "0-1", RestartPolicy: "no"}},
+		},
+		stats: map[string]*docker.Stats{
+			// cgroup v1: per-CPU usage reported, idle CPUs skipped
+			"v1aabbccddeeff001122": {CPUUsageTotal: 3e9, CPUUsagePerCPU: []uint64{1e9, 0, 2e9}},
+			// cgroup v2: no per-CPU data
+			"pinnedaabbccddeeff00": {CPUUsageTotal: 4e9},
+			"spreadaabbccddeeff00": {CPUUsageTotal: 5e9},
+		},
+	}
+
+	cfg := newTestConfig()
+	collector := NewContainerCollector(mock, newTestFilter(), NewStatsCache(0, false), nil, nil, nil, cfg)
+	assert.Empty(t, findMetric(collectMetrics(collector), "container_cpu_usage_per_cpu_seconds_total"), "opt-in")
+
+	cfg.Collection.Stats.PerCPU = true
+	collector = NewContainerCollector(mock, newTestFilter(), NewStatsCache(0, false), nil, nil, nil, cfg)
+
+	got := map[string]float64{}
+	for _, m := range findMetric(collectMetrics(collector), "container_cpu_usage_per_cpu_seconds_total") {
+		l := metricLabels(t, m)
+		got[l["container_name"]+"/"+l["cpu"]] = counterValue(t, m)
+	}
+	assert.Equal(t, map[string]float64{
+		"v1/0":     1,
+		"v1/2":     2,
+		"pinned/2": 4,
+	}, got)
+}
+
+func TestCollect_CPUUsageRatio(t *testing.T) {
+	mock := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "limitedaabbccddeeff0", Name: "limited", Image: "app:1", State: "running", Limits: docker.Limits{NanoCPUs: 2e9, RestartPolicy: "no"}},
+			{ID: "unlimitedaabbccddeef", Name: "unlimited", Image: "app:1", State: "running", Limits: docker.Limits{RestartPolicy: "no"}},
+			{ID: "firstaabbccddeeff001", Name: "first", Image: "app:1", State: "running"},
+		},
+		stats: map[string]*docker.Stats{
+			"limitedaabbccddeeff0": {CPUUsageRatio: 1.5, HasCPUUsageRatio: true},
+			"unlimitedaabbccddeef": {CPUUsageRatio: 0.25, HasCPUUsageRatio: true},
+			// No previous sample yet
+			"firstaabbccddeeff001": {},
+		},
+	}
+
+	collector := NewContainerCollector(mock, newTestFilter(), NewStatsCache(0, false), nil, nil, nil, newTestConfig())
+	collected := collectMetrics(collector)
+
+	usage := map[string]float64{}
+	for _, m := range findMetric(collected, "container_cpu_usage_ratio") {
+		usage[metricLabels(t, m)["container_name"]] = gaugeValue(t, m)
+	}
+	assert.Equal(t, map[string]float64{"limited": 1.5, "unlimited": 0.25}, usage)
+
+	ofLimit := findMetric(collected, "container_cpu_limit_usage_ratio")
+	require.Len(t, ofLimit, 1, "only containers with a CPU limit")
+	assert.Equal(t, "limited", metricLabels(t, ofLimit[0])["container_name"])
+	assert.Equal(t, 0.75, gaugeValue(t, ofLimit[0]))
+}
+
+type fakeHostInfo struct {
+	memTotal int64
+	ncpu     int
+}
+
+func (f *fakeHostInfo) GetHostInfo(_ context.Context) (*docker.HostInfo, error) {
+	return &docker.HostInfo{MemTotal: f.memTotal, NCPU: f.ncpu}, nil
+}
+
+func TestCollect_MemoryRatios(t *testing.T) {
+	mock := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "limitedaabbccddeeff0", Name: "limited", Image: "app:1", State: "running", Limits: docker.Limits{Memory: 1 << 30, RestartPolicy: "no"}},
+			{ID: "unlimitedaabbccddeef", Name: "unlimited", Image: "app:1", State: "running", Limits: docker.Limits{RestartPolicy: "no"}},
+		},
+		stats: map[string]*docker.Stats{
+			"limitedaabbccddeeff0": {MemoryUsage: 512 << 20, MemoryWorkingSet: 256 << 20, MemoryLimit: 1 << 30},
+			// The API reports host memory as the limit
+			"unlimitedaabbccddeef": {MemoryUsage: 2 << 30, MemoryWorkingSet: 1 << 30, MemoryLimit: 8 << 30},
+		},
+	}
+
+	type ratios struct{ usage, workingSet, unlimited float64 }
+	collect := func(host *docker.HostResources) map[string]ratios {
+		collector := NewContainerCollector(mock, newTestFilter(), NewStatsCache(0, false), nil, host, nil, newTestConfig())
+		collected := collectMetrics(collector)
+		got := map[string]ratios{}
+		for name, field := range map[string]func(*ratios) *float64{
+			"container_memory_usage_ratio":       func(r *ratios) *float64 { return &r.usage },
+			"container_memory_working_set_ratio": func(r *ratios) *float64 { return &r.workingSet },
+			"container_memory_unlimited":         func(r *ratios) *float64 { return &r.unlimited },
+		} {
+			for _, m := range findMetric(collected, name) {
+				ctr := metricLabels(t, m)["container_name"]
+				r := got[ctr]
+				*field(&r) = gaugeValue(t, m)
+				got[ctr] = r
+			}
+		}
+		return got
+	}
+
+	want := map[string]ratios{
+		"limited":   {usage: 0.5, workingSet: 0.25, unlimited: 0},
+		"unlimited": {usage: 0.25, workingSet: 0.125, unlimited: 1},
+	}
+	assert.Equal(t, want, collect(docker.NewHostResources(&fakeHostInfo{memTotal: 8 << 30, ncpu: 4})))
+	assert.Equal(t, want, collect(nil), "falls back to the inspected limits")
+}
+
+func TestCollect_HostShare(t *testing.T) {
+	mock := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "busyaabbccddeeff0011", Name: "busy", Image: "app:1", State: "running"},
+			{ID: "firstaabbccddeeff001", Name: "first", Image: "app:1", State: "running"},
+		},
+		stats: map[string]*docker.Stats{
+			"busyaabbccddeeff0011": {CPUUsageRatio: 2, HasCPUUsageRatio: true, MemoryWorkingSet: 2 << 30},
+			// No previous sample: no CPU share yet
+			"firstaabbccddeeff001": {MemoryWorkingSet: 1 << 30},
+		},
+	}
+
+	host := docker.NewHostResources(&fakeHostInfo{memTotal: 8 << 30, ncpu: 8})
+	collector := NewContainerCollector(mock, newTestFilter(), NewStatsCache(0, false), nil, host, nil, newTestConfig())
+	collected := collectMetrics(collector)
+
+	cpu := findMetric(collected, "container_cpu_host_share_ratio")
+	require.Len(t, cpu, 1)
+	assert.Equal(t, "busy", metricLabels(t, cpu[0])["container_name"])
+	assert.Equal(t, 0.25, gaugeValue(t, cpu[0]))
+
+	mem := map[string]float64{}
+	for _, m := range findMetric(collected, "container_memory_host_share_ratio") {
+		mem[metricLabels(t, m)["container_name"]] = gaugeValue(t, m)
+	}
+	assert.Equal(t, map[string]float64{"busy": 0.25, "first": 0.125}, mem)
+
+	// Without host resources there is nothing to share against
+	collector = NewContainerCollector(mock, newTestFilter(), NewStatsCache(0, false), nil, nil, nil, newTestConfig())
+	collected = collectMetrics(collector)
+	assert.Empty(t, findMetric(collected, "container_cpu_host_share_ratio"))
+	assert.Empty(t, findMetric(collected, "container_memory_host_share_ratio"))
+}
+
+func TestCollect_NetworkInterfaceInfo(t *testing.T) {
+	traffic := map[string]docker.NetworkStats{"eth0": {RxBytes: 1}, "eth1": {RxBytes: 2}}
+	mock := &mockDockerClient{
+		containers: []docker.Container{
+			{
+				ID: "twonetsaabbccddeeff0", Name: "api", Image: "api:1", State: "running",
+				Pid: 4242, NetworkMode: "app_frontend",
+				Endpoints: []docker.Endpoint{
+					{Network: "app_backend", Driver: "bridge", IPAddress: "172.17.0.3"},
+					{Network: "app_frontend", Driver: "bridge", IPAddress: "172.20.0.5"},
+				},
+			},
+		},
+		stats: map[string]*docker.Stats{"twonetsaabbccddeeff0": {Networks: traffic}},
+	}
+
+	interfaces := func(proc *procfs.Reader) map[string]string {
+		collector := NewContainerCollector(mock, newTestFilter(), NewStatsCache(0, false), nil, nil, proc, newTestConfig())
+		got := map[string]string{}
+		for _, m := range findMetric(collectMetrics(collector), "container_network_interface_info") {
+			l := metricLabels(t, m)
+			got[l["interface"]] = l["network_name"] + " " + l["network_driver"] + " " + l["ip_address"]
+		}
+		return got
+	}
+
+	// The testdata routing table puts 172.17.0.0/16 on eth0 and 172.20.0.0/24 on eth1
+	assert.Equal(t, map[string]string{
+		"eth0": "app_backend bridge 172.17.0.3",
+		"eth1": "app_frontend bridge 172.20.0.5",
+	}, interfaces(procfs.NewReader("../../testdata/proc")))
+
+	// Without /proc only the primary network's eth0 is known
+	assert.Equal(t, map[string]string{
+		"eth0": "app_frontend bridge 172.20.0.5",
+	}, interfaces(nil))
+}
+
+func TestCollect_OOMKilled(t *testing.T) {
+	mock := &mockDockerClient{
+		containers: []docker.Container{
+			{ID: "oom1aabbccddeeff0011", Name: "hungry", Image: "app:1", State: "exited", ExitCode: 137, OOMKilled: true},
+			{ID: "kill1aabbccddeeff001", Name: "killed", Image: "app:1", State: "exited", ExitCode: 137},
+		},
+	}
+
+	cache := NewStatsCache(30*time.Second, false)
+	collector := NewContainerCollector(mock, newTestFilter(), cache, nil, nil, nil, newTestConfig())
+
+	oom := map[string]float64{}
+	for _, m := range findMetric(collectMetrics(collector), "container_oom_killed") {
+		oom[metricLabels(t, m)["container_name"]] = gaugeValue(t, m)
+	}
+	assert.Equal(t, map[string]float64{"hungry": 1, "killed": 0}, oom)
+}
+
 func TestCollect_ListError(t *testing.T) {
 	mock := &mockDockerClient{
 		listErr: fmt.Errorf("connection refused"),
 	}
 
 	cache := NewStatsCache(30*time.Second, false)
-	collector := NewContainerCollector(mock, newTestFilter(), cache, newTestConfig())
+	collector := NewContainerCollector(mock, newTestFilter(), cache, nil, nil, nil, newTestConfig())
 	metrics := collectMetrics(collector)
 
 	// Should only emit self-metrics (scrape duration + errors)
@@ -207,7 +566,7 @@ func TestCollect_StatsError(t *testing.T) {
 	}
 
 	cache := NewStatsCache(30*time.Second, false)
-	collector := NewContainerCollector(mock, newTestFilter(), cache, newTestConfig())
+	collector := NewContainerCollector(mock, newTestFilter(), cache, nil, nil, nil, newTestConfig())
 	metrics := collectMetrics(collector)
 
 	// Should still emit self-metrics even when stats fail
@@ -236,7 +595,7 @@ func TestCollect_CacheHit(t *testing.T) {
 	cache := NewStatsCache(30*time.Second, true)
 	cache.Set("cached1aabbccddeeff00", cachedStats)
 
-	collector := NewContainerCollector(mock, newTestFilter(), cache, newTestConfig())
+	collector := NewContainerCollector(mock, newTestFilter(), cache, nil, nil, nil, newTestConfig())
 	metrics := collectMetrics(collector)
 
 	// Should use cached stats — no call to GetContainerStats needed
@@ -264,7 +623,7 @@ func TestCollect_FilterExcludes(t *testing.T) {
 	require.NoError(t, err)
 
 	cache := NewStatsCache(30*time.Second, false)
-	collector := NewContainerCollector(mock, filter, cache, newTestConfig())
+	collector := NewContainerCollector(mock, filter, cache, nil, nil, nil, newTestConfig())
 	metrics := collectMetrics(collector)
 
 	memUsage := findMetric(metrics, "container_memory_usage_bytes")
@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/internal/metrics"
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+// DiskUsageGetter defines the Docker API methods needed by the disk usage
+// collector.
+type DiskUsageGetter interface {
+	GetDiskUsage(ctx context.Context) (*docker.DiskUsage, error)
+}
+
+// DiskUsageCollector reports `docker system df` data. The call is expensive,
+// so it runs in the background on its own interval and scrapes replay the
+// last result. A failing call doesn't touch exporter_up; the previous result
+// keeps being served and docker_disk_usage_age_seconds grows.
+type DiskUsageCollector struct {
+	*refresher[*docker.DiskUsage]
+}
+
+// NewDiskUsageCollector creates a disk usage collector. Call Run to start
+// refreshing; until the first call succeeds, Collect emits only self-metrics.
+func NewDiskUsageCollector(client DiskUsageGetter, cfg *config.Config) *DiskUsageCollector {
+	du := cfg.Collection.DiskUsage
+	return &DiskUsageCollector{newRefresher("disk_usage", du.Interval, du.Timeout, client.GetDiskUsage)}
+}
+
+// Describe sends all disk usage metric descriptors.
+func (c *DiskUsageCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range metrics.AllDiskUsageDescs() {
+		ch <- d
+	}
+}
+
+// Collect replays the latest disk usage sample.
+func (c *DiskUsageCollector) Collect(ch chan<- prometheus.Metric) {
+	du, ok := c.latest(ch, metrics.DiskUsageAge)
+	if !ok {
+		return
+	}
+
+	for _, cat := range []struct {
+		name string
+		c    docker.DiskUsageCategory
+	}{
+		{"images", du.Images},
+		{"containers", du.Containers},
+		{"volumes", du.Volumes},
+		{"build_cache", du.BuildCache},
+	} {
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.DiskUsageBytes, prometheus.GaugeValue, float64(cat.c.Size), cat.name))
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.DiskUsageReclaimable, prometheus.GaugeValue, float64(cat.c.Reclaimable), cat.name))
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.DiskUsageObjects, prometheus.GaugeValue, float64(cat.c.Active), cat.name, "true"))
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.DiskUsageObjects, prometheus.GaugeValue, float64(cat.c.Count-cat.c.Active), cat.name, "false"))
+	}
+
+	for _, v := range du.VolumeDetails {
+		lv := []string{docker.SanitizeLabelValue(v.Name), docker.SanitizeLabelValue(v.Driver)}
+		if v.Size >= 0 {
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.VolumeSize, prometheus.GaugeValue, float64(v.Size), lv...))
+		}
+		if v.RefCount >= 0 {
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.VolumeRefCount, prometheus.GaugeValue, float64(v.RefCount), lv...))
+		}
+	}
+}
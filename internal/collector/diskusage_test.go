@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+)
+
+type mockDiskUsageGetter struct {
+	usage *docker.DiskUsage
+	err   error
+}
+
+func (m *mockDiskUsageGetter) GetDiskUsage(_ context.Context) (*docker.DiskUsage, error) {
+	return m.usage, m.err
+}
+
+func newDiskUsageTestCollector(mock *mockDiskUsageGetter) *DiskUsageCollector {
+	cfg := newTestConfig()
+	cfg.Collection.DiskUsage.Interval = time.Minute
+	cfg.Collection.DiskUsage.Timeout = time.Second
+	return NewDiskUsageCollector(mock, cfg)
+}
+
+func TestDiskUsageCollector_EmptyBeforeFirstRefresh(t *testing.T) {
+	c := newDiskUsageTestCollector(&mockDiskUsageGetter{})
+
+	collected := collectMetrics(c)
+	assert.Empty(t, findMetric(collected, "docker_disk_usage_bytes"))
+	assert.Len(t, findMetric(collected, "exporter_scrape_errors_total"), 1)
+}
+
+func TestDiskUsageCollector_ServesLastSuccessfulRefresh(t *testing.T) {
+	mock := &mockDiskUsageGetter{usage: &docker.DiskUsage{
+		Images:     docker.DiskUsageCategory{Count: 3, Active: 1, Size: 3000, Reclaimable: 2000},
+		BuildCache: docker.DiskUsageCategory{Count: 2, Size: 500, Reclaimable: 500},
+		VolumeDetails: []docker.VolumeUsage{
+			{Name: "pgdata", Driver: "local", Size: 1024, RefCount: 1},
+			{Name: "nfs", Driver: "nfs", Size: -1, RefCount: 2},
+		},
+	}}
+	c := newDiskUsageTestCollector(mock)
+	c.refresh(context.Background())
+
+	// A later failure keeps the previous data and counts the error
+	mock.usage, mock.err = nil, fmt.Errorf("daemon busy")
+	c.refresh(context.Background())
+
+	collected := collectMetrics(c)
+
+	bytes := findMetric(collected, "docker_disk_usage_bytes")
+	require.Len(t, bytes, 4)
+	values := make(map[string]float64)
+	for _, m := range bytes {
+		values[metricLabels(t, m)["type"]] = gaugeValue(t, m)
+	}
+	assert.Equal(t, float64(3000), values["images"])
+	assert.Equal(t, float64(500), values["build_cache"])
+
+	assert.Len(t, findMetric(collected, "docker_volume_size_bytes"), 1, "no size for non-local drivers")
+	assert.Len(t, findMetric(collected, "docker_volume_ref_count"), 2)
+	assert.Len(t, findMetric(collected, "docker_disk_usage_age_seconds"), 1)
+
+	errs := findMetric(collected, "exporter_scrape_errors_total")
+	require.Len(t, errs, 1)
+	d := &dto.Metric{}
+	require.NoError(t, errs[0].Write(d))
+	assert.Equal(t, float64(1), d.GetCounter().GetValue())
+}
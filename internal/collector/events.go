@@ -0,0 +1,42 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/internal/metrics"
+)
+
+// EventCounter defines the methods needed by the events collector.
+type EventCounter interface {
+	EventCounts() []docker.EventCount
+}
+
+// EventsCollector reports container lifecycle event counts maintained from
+// the events stream. Unlike container_restart_count, the counters survive a
+// container being recreated, since they are grouped by compose service.
+type EventsCollector struct {
+	counter EventCounter
+}
+
+// NewEventsCollector creates an events collector. The counter is expected to
+// be following events already, with container filters applied.
+func NewEventsCollector(counter EventCounter) *EventsCollector {
+	return &EventsCollector{counter: counter}
+}
+
+// Describe sends the events metric descriptors.
+func (c *EventsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- metrics.ContainerEvents
+}
+
+// Collect emits one counter per action and compose service.
+func (c *EventsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, e := range c.counter.EventCounts() {
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ContainerEvents, prometheus.CounterValue, float64(e.Count),
+			e.Action,
+			docker.SanitizeLabelValue(e.ComposeService),
+			docker.SanitizeLabelValue(e.ComposeProject),
+		))
+	}
+}
@@ -0,0 +1,28 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+)
+
+func TestEventsCollector(t *testing.T) {
+	c := NewEventsCollector(&mockEventCounter{events: []docker.EventCount{
+		{Action: "die", ComposeService: "web", ComposeProject: "shop", Count: 4},
+		{Action: "start", ComposeService: "web", ComposeProject: "shop", Count: 5},
+		{Action: "start", Count: 1},
+	}})
+
+	got := map[string]float64{}
+	for _, m := range findMetric(collectMetrics(c), "docker_container_events_total") {
+		l := metricLabels(t, m)
+		got[l["action"]+"/"+l["compose_project"]+"/"+l["compose_service"]] = counterValue(t, m)
+	}
+	assert.Equal(t, map[string]float64{
+		"die/shop/web":   4,
+		"start/shop/web": 5,
+		"start//":        1,
+	}, got)
+}
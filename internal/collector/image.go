@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/internal/metrics"
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+// ImageLister defines the Docker API methods needed by the image collector.
+type ImageLister interface {
+	ListImages(ctx context.Context) ([]docker.Image, error)
+}
+
+// ImageCollector implements prometheus.Collector for per-image metrics.
+type ImageCollector struct {
+	client    ImageLister
+	filter    *docker.ImageFilter
+	maxImages int
+	timeout   time.Duration
+}
+
+// NewImageCollector creates a new image metrics collector.
+func NewImageCollector(client ImageLister, filter *docker.ImageFilter, cfg *config.Config) *ImageCollector {
+	return &ImageCollector{
+		client:    client,
+		filter:    filter,
+		maxImages: cfg.Collection.Images.MaxImages,
+		timeout:   cfg.Collection.Timeout,
+	}
+}
+
+// Describe sends all image metric descriptors.
+func (c *ImageCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range metrics.AllImageDescs() {
+		ch <- d
+	}
+}
+
+// Collect lists images and emits metrics for those passing the filter, up to
+// the cardinality cap.
+func (c *ImageCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	var scrapeErrors int64
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	images, err := c.client.ListImages(ctx)
+	if err != nil {
+		log.WithError(err).Error("Failed to list images")
+		scrapeErrors++
+	} else {
+		c.emitImageMetrics(ch, images)
+	}
+
+	duration := time.Since(start).Seconds()
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ExporterScrapeDuration, prometheus.GaugeValue, duration, "image"))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ExporterScrapeErrors, prometheus.CounterValue, float64(scrapeErrors), "image"))
+}
+
+func (c *ImageCollector) emitImageMetrics(ch chan<- prometheus.Metric, images []docker.Image) {
+	var selected []docker.Image
+	for i := range images {
+		if c.filter.Match(&images[i]) {
+			selected = append(selected, images[i])
+		}
+	}
+
+	// Over the cap, keep the largest images: those are the ones disk alerts
+	// care about.
+	var dropped int
+	if c.maxImages > 0 && len(selected) > c.maxImages {
+		sort.SliceStable(selected, func(i, j int) bool { return selected[i].Size > selected[j].Size })
+		dropped = len(selected) - c.maxImages
+		selected = selected[:c.maxImages]
+	}
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ImagesDropped, prometheus.GaugeValue, float64(dropped)))
+
+	for i := range selected {
+		img := &selected[i]
+		lv := []string{
+			shortImageID(img.ID),
+			docker.SanitizeLabelValue(img.Repository),
+			docker.SanitizeLabelValue(img.Tag),
+		}
+
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ImageSize, prometheus.GaugeValue, float64(img.Size), lv...))
+		if img.SharedSize >= 0 {
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ImageSharedSize, prometheus.GaugeValue, float64(img.SharedSize), lv...))
+		}
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ImageCreated, prometheus.GaugeValue, float64(img.Created.Unix()), lv...))
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ImageContainers, prometheus.GaugeValue, float64(img.Containers), lv...))
+
+		var dangling float64
+		if img.Dangling {
+			dangling = 1
+		}
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ImageDangling, prometheus.GaugeValue, dangling, lv...))
+	}
+}
+
+// shortImageID trims the digest algorithm and shortens the ID to 12
+// characters, as `docker images` shows it.
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return id
+}
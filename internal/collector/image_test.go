@@ -0,0 +1,91 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+type mockImageLister struct {
+	images []docker.Image
+}
+
+func (m *mockImageLister) ListImages(_ context.Context) ([]docker.Image, error) {
+	return m.images, nil
+}
+
+func newImageTestCollector(t *testing.T, images []docker.Image, imagesCfg config.ImagesConfig) *ImageCollector {
+	t.Helper()
+	filter, err := docker.NewImageFilter(imagesCfg)
+	require.NoError(t, err)
+
+	cfg := newTestConfig()
+	cfg.Collection.Images = imagesCfg
+	return NewImageCollector(&mockImageLister{images: images}, filter, cfg)
+}
+
+func TestImageCollector_EmitsPerImage(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	images := []docker.Image{
+		{ID: "sha256:0123456789abcdef0123", Repository: "nginx", Tag: "1.27", Size: 190 << 20, SharedSize: 80 << 20, Created: created, Containers: 2},
+		{ID: "sha256:fedcba9876543210fedc", Size: 50 << 20, SharedSize: -1, Created: created, Dangling: true},
+	}
+
+	collected := collectMetrics(newImageTestCollector(t, images, config.ImagesConfig{}))
+
+	sizes := findMetric(collected, "docker_image_size_bytes")
+	require.Len(t, sizes, 2)
+	assert.Equal(t, map[string]string{"image_id": "0123456789ab", "repository": "nginx", "tag": "1.27"}, metricLabels(t, sizes[0]))
+	assert.Equal(t, float64(190<<20), gaugeValue(t, sizes[0]))
+
+	// Shared size is skipped when the daemon didn't compute it
+	assert.Len(t, findMetric(collected, "docker_image_shared_size_bytes"), 1)
+
+	createdTS := findMetric(collected, "docker_image_created_timestamp_seconds")
+	require.Len(t, createdTS, 2)
+	assert.Equal(t, float64(created.Unix()), gaugeValue(t, createdTS[0]))
+
+	containers := findMetric(collected, "docker_image_containers")
+	require.Len(t, containers, 2)
+	assert.Equal(t, float64(2), gaugeValue(t, containers[0]))
+	assert.Equal(t, float64(0), gaugeValue(t, containers[1]))
+
+	dangling := findMetric(collected, "docker_image_dangling")
+	require.Len(t, dangling, 2)
+	assert.Equal(t, float64(0), gaugeValue(t, dangling[0]))
+	assert.Equal(t, float64(1), gaugeValue(t, dangling[1]))
+
+	dropped := findMetric(collected, "docker_images_dropped")
+	require.Len(t, dropped, 1)
+	assert.Equal(t, float64(0), gaugeValue(t, dropped[0]))
+}
+
+func TestImageCollector_CapKeepsLargest(t *testing.T) {
+	images := []docker.Image{
+		{ID: "sha256:aaaaaaaaaaaaaaaa", Repository: "small", Tag: "1", Size: 10},
+		{ID: "sha256:bbbbbbbbbbbbbbbb", Repository: "big", Tag: "1", Size: 1000},
+		{ID: "sha256:cccccccccccccccc", Repository: "medium", Tag: "1", Size: 100},
+		{ID: "sha256:dddddddddddddddd", Repository: "denied", Tag: "1", Size: 5000},
+	}
+
+	collected := collectMetrics(newImageTestCollector(t, images, config.ImagesConfig{
+		Deny:      []string{"^denied:"},
+		MaxImages: 2,
+	}))
+
+	sizes := findMetric(collected, "docker_image_size_bytes")
+	require.Len(t, sizes, 2)
+	assert.Equal(t, "big", metricLabels(t, sizes[0])["repository"])
+	assert.Equal(t, "medium", metricLabels(t, sizes[1])["repository"])
+
+	// Denied images don't count against the cap
+	dropped := findMetric(collected, "docker_images_dropped")
+	require.Len(t, dropped, 1)
+	assert.Equal(t, float64(1), gaugeValue(t, dropped[0]))
+}
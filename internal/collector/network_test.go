@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+)
+
+type mockNetworkLister struct {
+	networks []docker.Network
+	err      error
+}
+
+func (m *mockNetworkLister) ListNetworks(_ context.Context) ([]docker.Network, error) {
+	return m.networks, m.err
+}
+
+func TestNetworkCollector_EmitsPerNetwork(t *testing.T) {
+	networks := []docker.Network{
+		{
+			ID: "0123456789abcdef0123", Name: "bridge", Driver: "bridge", Scope: "local",
+			Containers: 2,
+			Subnets:    []docker.Subnet{{Subnet: "172.17.0.0/16", Capacity: 65534, Allocated: 3}},
+		},
+		{
+			ID: "fedcba9876543210fedc", Name: "backend", Driver: "bridge", Scope: "local", Internal: true, Attachable: true,
+			Subnets: []docker.Subnet{
+				{Subnet: "10.1.0.0/28", Capacity: 14, Allocated: 14},
+				{Subnet: "fd00:1::/64", Capacity: 1 << 64, Allocated: 1},
+			},
+		},
+		{ID: "aaaaaaaaaaaaaaaaaaaa", Name: "host", Driver: "host", Scope: "local", Containers: 1},
+	}
+
+	collected := collectMetrics(NewNetworkCollector(&mockNetworkLister{networks: networks}, newTestConfig()))
+
+	info := findMetric(collected, "docker_network_info")
+	require.Len(t, info, 3)
+	assert.Equal(t, map[string]string{
+		"network_id": "fedcba987654", "network_name": "backend",
+		"driver": "bridge", "scope": "local", "internal": "true", "attachable": "true",
+	}, metricLabels(t, info[1]))
+
+	containers := findMetric(collected, "docker_network_containers")
+	require.Len(t, containers, 3)
+	assert.Equal(t, float64(2), gaugeValue(t, containers[0]))
+	assert.Equal(t, float64(0), gaugeValue(t, containers[1]))
+
+	// Networks without IPAM config (host, none) have no subnet series
+	capacity := findMetric(collected, "docker_network_subnet_addresses")
+	require.Len(t, capacity, 3)
+	assert.Equal(t, map[string]string{"network_id": "fedcba987654", "network_name": "backend", "subnet": "10.1.0.0/28"}, metricLabels(t, capacity[1]))
+	assert.Equal(t, float64(14), gaugeValue(t, capacity[1]))
+
+	allocated := findMetric(collected, "docker_network_subnet_allocated_addresses")
+	require.Len(t, allocated, 3)
+	assert.Equal(t, float64(3), gaugeValue(t, allocated[0]))
+	assert.Equal(t, float64(14), gaugeValue(t, allocated[1]))
+}
+
+func TestNetworkCollector_ListError(t *testing.T) {
+	collected := collectMetrics(NewNetworkCollector(&mockNetworkLister{err: errors.New("daemon unavailable")}, newTestConfig()))
+
+	assert.Empty(t, findMetric(collected, "docker_network_info"))
+	errs := findMetric(collected, "exporter_scrape_errors_total")
+	require.Len(t, errs, 1)
+	assert.Equal(t, float64(1), counterValue(t, errs[0]))
+}
@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/internal/metrics"
+)
+
+// OOMCounter defines the methods needed by the OOM collector.
+type OOMCounter interface {
+	OOMCounts() []docker.OOMCount
+}
+
+// OOMCollector reports OOM kill counts maintained from the events stream.
+type OOMCollector struct {
+	counter OOMCounter
+}
+
+// NewOOMCollector creates an OOM collector. The counter is expected to be
+// following events already, with container filters applied.
+func NewOOMCollector(counter OOMCounter) *OOMCollector {
+	return &OOMCollector{counter: counter}
+}
+
+// Describe sends the OOM metric descriptors.
+func (c *OOMCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- metrics.ContainerOOMEvents
+}
+
+// Collect emits one counter per container that has been OOM killed since the
+// exporter started.
+func (c *OOMCollector) Collect(ch chan<- prometheus.Metric) {
+	counts := c.counter.OOMCounts()
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].Container.Name < counts[j].Container.Name
+	})
+
+	for i := range counts {
+		lv := docker.ExtractLabels(&counts[i].Container).Values()
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ContainerOOMEvents, prometheus.CounterValue, float64(counts[i].Count), lv...))
+	}
+}
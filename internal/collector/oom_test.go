@@ -0,0 +1,47 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+)
+
+type mockEventCounter struct {
+	events []docker.EventCount
+	ooms   []docker.OOMCount
+}
+
+func (m *mockEventCounter) EventCounts() []docker.EventCount {
+	return m.events
+}
+
+func (m *mockEventCounter) OOMCounts() []docker.OOMCount {
+	return m.ooms
+}
+
+func counterValue(t *testing.T, m prometheus.Metric) float64 {
+	t.Helper()
+	d := &dto.Metric{}
+	require.NoError(t, m.Write(d))
+	return d.GetCounter().GetValue()
+}
+
+func TestOOMCollector_Labels(t *testing.T) {
+	c := NewOOMCollector(&mockEventCounter{ooms: []docker.OOMCount{
+		{Container: docker.Container{ID: "a", Name: "web", Image: "nginx", Labels: map[string]string{"com.docker.compose.service": "web"}}, Count: 3},
+		{Container: docker.Container{ID: "b", Name: "worker", Image: "busybox"}, Count: 1},
+	}})
+
+	found := findMetric(collectMetrics(c), "container_oom_events_total")
+	require.Len(t, found, 2)
+
+	labels := metricLabels(t, found[0])
+	assert.Equal(t, "web", labels["container_name"])
+	assert.Equal(t, "web", labels["compose_service"])
+	assert.Equal(t, float64(3), counterValue(t, found[0]))
+}
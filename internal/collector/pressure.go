@@ -0,0 +1,113 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/cgroup"
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/internal/metrics"
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+// PressureReader defines the cgroup methods needed by the pressure collector.
+type PressureReader interface {
+	Pressure(ctr *docker.Container) (*cgroup.PSI, error)
+}
+
+// PressureCollector reports cgroup v2 pressure stall information for running
+// containers, read from each container's cgroup directory.
+type PressureCollector struct {
+	lister  docker.ContainerLister
+	reader  PressureReader
+	filter  *docker.Filter
+	timeout time.Duration
+
+	unavailable sync.Once
+}
+
+// NewPressureCollector creates a pressure collector. Containers are listed
+// through lister (the client itself or an Inventory).
+func NewPressureCollector(lister docker.ContainerLister, reader PressureReader, filter *docker.Filter, cfg *config.Config) *PressureCollector {
+	return &PressureCollector{
+		lister:  lister,
+		reader:  reader,
+		filter:  filter,
+		timeout: cfg.Collection.Timeout,
+	}
+}
+
+// Describe sends all pressure metric descriptors.
+func (c *PressureCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range metrics.AllPressureDescs() {
+		ch <- d
+	}
+}
+
+// Collect reads PSI files for every running container that passes the filter.
+func (c *PressureCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	var scrapeErrors int64
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	containers, err := c.lister.ListContainers(ctx)
+	if err != nil {
+		log.WithError(err).Error("Failed to list containers")
+		scrapeErrors++
+	}
+
+	for i := range containers {
+		ctr := &containers[i]
+		if ctr.State != "running" || !c.filter.Match(ctr) {
+			continue
+		}
+
+		psi, err := c.reader.Pressure(ctr)
+		if err != nil {
+			log.WithError(err).WithField("container", ctr.Name).Warn("Failed to read pressure stall information, skipping")
+			scrapeErrors++
+			continue
+		}
+		if psi.CPU == nil && psi.Memory == nil && psi.IO == nil {
+			c.unavailable.Do(func() {
+				log.Warn("Pressure stall information is not available; the kernel needs CONFIG_PSI and must not be booted with psi=0")
+			})
+			continue
+		}
+
+		lv := docker.ExtractLabels(ctr).Values()
+		emitPressure(ch, psi.CPU, "cpu", lv)
+		emitPressure(ch, psi.Memory, "memory", lv)
+		emitPressure(ch, psi.IO, "io", lv)
+	}
+
+	duration := time.Since(start).Seconds()
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ExporterScrapeDuration, prometheus.GaugeValue, duration, "pressure"))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ExporterScrapeErrors, prometheus.CounterValue, float64(scrapeErrors), "pressure"))
+}
+
+func emitPressure(ch chan<- prometheus.Metric, p *cgroup.Pressure, resource string, lv []string) {
+	if p == nil {
+		return
+	}
+	emitPressureLine(ch, p.Some, append(lv, resource, "some"))
+	emitPressureLine(ch, p.Full, append(lv, resource, "full"))
+}
+
+// emitPressureLine converts totals from microseconds to seconds and averages
+// from percentages to ratios.
+func emitPressureLine(ch chan<- prometheus.Metric, l *cgroup.PressureLine, plv []string) {
+	if l == nil {
+		return
+	}
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.PressureStalled, prometheus.CounterValue, float64(l.Total)/1e6, plv...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.PressureAvg, prometheus.GaugeValue, l.Avg10/100, append(plv, "10s")...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.PressureAvg, prometheus.GaugeValue, l.Avg60/100, append(plv, "60s")...))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.PressureAvg, prometheus.GaugeValue, l.Avg300/100, append(plv, "300s")...))
+}
@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/cgroup"
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+)
+
+type mockPressureReader struct {
+	psi map[string]*cgroup.PSI
+}
+
+func (m *mockPressureReader) Pressure(ctr *docker.Container) (*cgroup.PSI, error) {
+	if p, ok := m.psi[ctr.ID]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("cgroup for container %s not found", ctr.ID)
+}
+
+func TestPressureCollector(t *testing.T) {
+	lister := &mockDockerClient{containers: []docker.Container{
+		{ID: "a", Name: "web", Image: "nginx", State: "running"},
+		{ID: "b", Name: "nopsi", Image: "nginx", State: "running"},
+		{ID: "c", Name: "stopped", Image: "nginx", State: "exited"},
+		{ID: "d", Name: "gone", Image: "nginx", State: "running"},
+	}}
+	reader := &mockPressureReader{psi: map[string]*cgroup.PSI{
+		"a": {
+			CPU: &cgroup.Pressure{
+				Some: &cgroup.PressureLine{Avg10: 1.5, Avg60: 0.75, Avg300: 0.25, Total: 2500000},
+			},
+			Memory: &cgroup.Pressure{
+				Some: &cgroup.PressureLine{Total: 4000000},
+				Full: &cgroup.PressureLine{Avg10: 10, Total: 3000000},
+			},
+		},
+		// PSI disabled in the kernel
+		"b": {},
+	}}
+
+	c := NewPressureCollector(lister, reader, newTestFilter(), newTestConfig())
+	collected := collectMetrics(c)
+
+	stalled := map[string]float64{}
+	for _, m := range findMetric(collected, "container_pressure_stalled_seconds_total") {
+		l := metricLabels(t, m)
+		assert.Equal(t, "web", l["container_name"])
+		stalled[l["resource"]+"/"+l["kind"]] = counterValue(t, m)
+	}
+	assert.Equal(t, map[string]float64{
+		"cpu/some":    2.5,
+		"memory/some": 4,
+		"memory/full": 3,
+	}, stalled)
+
+	avg := map[string]float64{}
+	for _, m := range findMetric(collected, "container_pressure_stall_ratio") {
+		l := metricLabels(t, m)
+		avg[l["resource"]+"/"+l["kind"]+"/"+l["window"]] = gaugeValue(t, m)
+	}
+	assert.Len(t, avg, 9)
+	assert.InDelta(t, 0.015, avg["cpu/some/10s"], 1e-9)
+	assert.InDelta(t, 0.0025, avg["cpu/some/300s"], 1e-9)
+	assert.InDelta(t, 0.1, avg["memory/full/10s"], 1e-9)
+
+	errors := findMetric(collected, "exporter_scrape_errors_total")
+	require.Len(t, errors, 1)
+	assert.Equal(t, 1.0, counterValue(t, errors[0]), "missing cgroup counts as an error")
+}
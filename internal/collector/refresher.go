@@ -0,0 +1,94 @@
+package collector
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/metrics"
+)
+
+// refreshed is one successful fetch.
+type refreshed[T any] struct {
+	value    T
+	taken    time.Time
+	duration time.Duration
+}
+
+// refresher fetches data too expensive to gather on every scrape in the
+// background, on its own interval, and keeps the last successful result for
+// scrapes to replay. A failed fetch counts an error and keeps the previous
+// result; it doesn't touch exporter_up.
+type refresher[T any] struct {
+	name     string // collector label of the self-metrics
+	interval time.Duration
+	timeout  time.Duration
+	fetch    func(ctx context.Context) (T, error)
+
+	current atomic.Pointer[refreshed[T]]
+	errors  atomic.Int64
+}
+
+func newRefresher[T any](name string, interval, timeout time.Duration, fetch func(ctx context.Context) (T, error)) *refresher[T] {
+	return &refresher[T]{
+		name:     name,
+		interval: interval,
+		timeout:  timeout,
+		fetch:    fetch,
+	}
+}
+
+// Run refreshes immediately, then once per interval until ctx is done.
+func (r *refresher[T]) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.refresh(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.refresh(ctx)
+		}
+	}
+}
+
+// refresh fetches once and publishes the result.
+func (r *refresher[T]) refresh(ctx context.Context) {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	value, err := r.fetch(ctx)
+	if err != nil {
+		r.errors.Add(1)
+		log.WithError(err).WithField("collector", r.name).Error("Background refresh failed")
+		return
+	}
+
+	r.current.Store(&refreshed[T]{
+		value:    value,
+		taken:    time.Now(),
+		duration: time.Since(start),
+	})
+}
+
+// latest sends the self-metrics and the age of the last result on age, and
+// returns that result. ok is false until the first fetch succeeds, when only
+// the error counter is sent.
+func (r *refresher[T]) latest(ch chan<- prometheus.Metric, age *prometheus.Desc) (value T, ok bool) {
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ExporterScrapeErrors, prometheus.CounterValue, float64(r.errors.Load()), r.name))
+
+	sample := r.current.Load()
+	if sample == nil {
+		return value, false
+	}
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ExporterScrapeDuration, prometheus.GaugeValue, sample.duration.Seconds(), r.name))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(age, prometheus.GaugeValue, time.Since(sample.taken).Seconds()))
+	return sample.value, true
+}
@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/internal/metrics"
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+// ContainerSizeLister defines the Docker API methods needed by the container
+// size collector.
+type ContainerSizeLister interface {
+	ListContainerSizes(ctx context.Context) ([]docker.Container, error)
+}
+
+// ContainerSizeCollector reports container writable-layer and root
+// filesystem sizes. Sizes are expensive to compute, so they are listed in the
+// background on their own interval and scrapes replay the last result.
+type ContainerSizeCollector struct {
+	*refresher[[]docker.Container]
+	client ContainerSizeLister
+	filter *docker.Filter
+}
+
+// NewContainerSizeCollector creates a container size collector. Call Run to
+// start refreshing; until the first listing succeeds, Collect emits only
+// self-metrics.
+func NewContainerSizeCollector(client ContainerSizeLister, filter *docker.Filter, cfg *config.Config) *ContainerSizeCollector {
+	c := &ContainerSizeCollector{client: client, filter: filter}
+	sizes := cfg.Collection.ContainerSize
+	c.refresher = newRefresher("container_size", sizes.Interval, sizes.Timeout, c.listSizes)
+	return c
+}
+
+// Describe sends all container size metric descriptors.
+func (c *ContainerSizeCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range metrics.AllContainerSizeDescs() {
+		ch <- d
+	}
+}
+
+// Collect replays the latest size listing.
+func (c *ContainerSizeCollector) Collect(ch chan<- prometheus.Metric) {
+	containers, ok := c.latest(ch, metrics.FSSizeAge)
+	if !ok {
+		return
+	}
+
+	for i := range containers {
+		ctr := &containers[i]
+		lv := docker.ExtractLabels(ctr).Values()
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSWritableLayer, prometheus.GaugeValue, float64(ctr.SizeRw), lv...))
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.FSRootfs, prometheus.GaugeValue, float64(ctr.SizeRootFs), lv...))
+	}
+}
+
+// listSizes lists container sizes, keeping the containers the filter matches.
+func (c *ContainerSizeCollector) listSizes(ctx context.Context) ([]docker.Container, error) {
+	containers, err := c.client.ListContainerSizes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []docker.Container
+	for i := range containers {
+		if c.filter.Match(&containers[i]) {
+			filtered = append(filtered, containers[i])
+		}
+	}
+	return filtered, nil
+}
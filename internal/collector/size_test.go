@@ -0,0 +1,64 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+type mockSizeLister struct {
+	containers []docker.Container
+	err        error
+	calls      int
+}
+
+func (m *mockSizeLister) ListContainerSizes(_ context.Context) ([]docker.Container, error) {
+	m.calls++
+	return m.containers, m.err
+}
+
+func TestContainerSizeCollector_ServesCachedSizes(t *testing.T) {
+	mock := &mockSizeLister{containers: []docker.Container{
+		{ID: "a", Name: "web", Image: "nginx", State: "running", SizeRw: 4096, SizeRootFs: 190 << 20},
+		{ID: "b", Name: "test-runner", Image: "busybox", State: "exited", SizeRw: 1 << 30, SizeRootFs: 1<<30 + 4<<20},
+	}}
+
+	filter, err := docker.NewFilter(config.FiltersConfig{
+		Exclude: config.FilterSet{Names: []string{"^test-"}},
+	})
+	require.NoError(t, err)
+
+	cfg := newTestConfig()
+	cfg.Collection.ContainerSize = config.ContainerSizeConfig{Interval: time.Minute, Timeout: time.Second}
+	c := NewContainerSizeCollector(mock, filter, cfg)
+
+	assert.Empty(t, findMetric(collectMetrics(c), "container_fs_writable_layer_bytes"), "nothing before the first refresh")
+
+	c.refresh(context.Background())
+
+	// Scrapes replay the cached listing without calling the daemon
+	collectMetrics(c)
+	collected := collectMetrics(c)
+	assert.Equal(t, 1, mock.calls)
+
+	rw := findMetric(collected, "container_fs_writable_layer_bytes")
+	require.Len(t, rw, 1)
+	assert.Equal(t, "web", metricLabels(t, rw[0])["container_name"])
+	assert.Equal(t, float64(4096), gaugeValue(t, rw[0]))
+
+	rootfs := findMetric(collected, "container_fs_rootfs_bytes")
+	require.Len(t, rootfs, 1)
+	assert.Equal(t, float64(190<<20), gaugeValue(t, rootfs[0]))
+
+	// A failed refresh keeps serving the previous sizes
+	mock.err = fmt.Errorf("timeout")
+	c.refresh(context.Background())
+	assert.Len(t, findMetric(collectMetrics(c), "container_fs_writable_layer_bytes"), 1)
+}
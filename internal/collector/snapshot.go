@@ -0,0 +1,102 @@
+package collector
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/metrics"
+)
+
+// snapshot is one complete collection pass. It is never modified after it is
+// published, so Collect can replay it without locking.
+type snapshot struct {
+	metrics []prometheus.Metric
+	taken   time.Time
+}
+
+// SnapshotCollector runs other collectors on a fixed interval and serves the
+// latest result from memory. Scrapes then cost nothing on the Docker side, no
+// matter how many Prometheus replicas hit the exporter.
+type SnapshotCollector struct {
+	collectors []prometheus.Collector
+	interval   time.Duration
+	current    atomic.Pointer[snapshot]
+}
+
+// NewSnapshotCollector wraps the given collectors. Call Run to start the
+// background loop; until the first pass completes, Collect emits nothing.
+func NewSnapshotCollector(interval time.Duration, collectors ...prometheus.Collector) *SnapshotCollector {
+	return &SnapshotCollector{
+		collectors: collectors,
+		interval:   interval,
+	}
+}
+
+// Describe sends the descriptors of all wrapped collectors.
+func (s *SnapshotCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range s.collectors {
+		c.Describe(ch)
+	}
+	ch <- metrics.ExporterSnapshotAge
+}
+
+// Collect replays the latest snapshot and reports its age.
+func (s *SnapshotCollector) Collect(ch chan<- prometheus.Metric) {
+	snap := s.current.Load()
+	if snap == nil {
+		return
+	}
+
+	for _, m := range snap.metrics {
+		ch <- m
+	}
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ExporterSnapshotAge, prometheus.GaugeValue, time.Since(snap.taken).Seconds()))
+}
+
+// Run collects immediately, then once per interval until ctx is done.
+func (s *SnapshotCollector) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.refresh()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refresh()
+		}
+	}
+}
+
+// refresh runs one full collection pass and publishes it.
+func (s *SnapshotCollector) refresh() {
+	start := time.Now()
+
+	ch := make(chan prometheus.Metric, 256)
+	go func() {
+		for _, c := range s.collectors {
+			c.Collect(ch)
+		}
+		close(ch)
+	}()
+
+	var collected []prometheus.Metric
+	if prev := s.current.Load(); prev != nil {
+		collected = make([]prometheus.Metric, 0, len(prev.metrics))
+	}
+	for m := range ch {
+		collected = append(collected, m)
+	}
+
+	s.current.Store(&snapshot{metrics: collected, taken: time.Now()})
+
+	log.WithFields(log.Fields{
+		"metrics":  len(collected),
+		"duration": time.Since(start).String(),
+	}).Debug("Collection snapshot refreshed")
+}
@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCollector emits one gauge whose value is the number of times
+// Collect has been called.
+type countingCollector struct {
+	desc  *prometheus.Desc
+	calls atomic.Int64
+}
+
+func newCountingCollector() *countingCollector {
+	return &countingCollector{desc: prometheus.NewDesc("test_collect_calls", "Collect calls.", nil, nil)}
+}
+
+func (c *countingCollector) Describe(ch chan<- *prometheus.Desc) { ch <- c.desc }
+
+func (c *countingCollector) Collect(ch chan<- prometheus.Metric) {
+	n := c.calls.Add(1)
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n))
+}
+
+func gaugeValue(t *testing.T, m prometheus.Metric) float64 {
+	t.Helper()
+	d := &dto.Metric{}
+	require.NoError(t, m.Write(d))
+	return d.GetGauge().GetValue()
+}
+
+func TestSnapshotCollector_EmptyBeforeFirstPass(t *testing.T) {
+	inner := newCountingCollector()
+	snap := NewSnapshotCollector(time.Minute, inner)
+
+	assert.Empty(t, collectMetrics(snap))
+	assert.Equal(t, int64(0), inner.calls.Load(), "Collect must not call through to wrapped collectors")
+}
+
+func TestSnapshotCollector_ReplaysSnapshot(t *testing.T) {
+	inner := newCountingCollector()
+	snap := NewSnapshotCollector(time.Minute, inner)
+	snap.refresh()
+
+	for i := 0; i < 3; i++ {
+		collected := collectMetrics(snap)
+
+		calls := findMetric(collected, "test_collect_calls")
+		require.Len(t, calls, 1)
+		assert.Equal(t, float64(1), gaugeValue(t, calls[0]), "scrapes should replay the same snapshot")
+
+		age := findMetric(collected, "exporter_snapshot_age_seconds")
+		require.Len(t, age, 1)
+		assert.GreaterOrEqual(t, gaugeValue(t, age[0]), float64(0))
+	}
+	assert.Equal(t, int64(1), inner.calls.Load())
+}
+
+func TestSnapshotCollector_RunRefreshes(t *testing.T) {
+	inner := newCountingCollector()
+	snap := NewSnapshotCollector(10*time.Millisecond, inner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go snap.Run(ctx)
+
+	assert.Eventually(t, func() bool { return inner.calls.Load() >= 3 }, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestSnapshotCollector_Registers(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(NewSnapshotCollector(time.Minute, newCountingCollector())))
+}
@@ -0,0 +1,143 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/internal/metrics"
+	"github.com/fabienpiette/docker-stats-exporter/internal/procfs"
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+// SocketReader defines the procfs methods needed by the socket collector.
+type SocketReader interface {
+	NetNS(pid int) (uint64, error)
+	Sockets(pid int) (*procfs.Sockets, error)
+}
+
+// SocketCollector reports TCP connection states and socket counts for running
+// containers, read from /proc/<pid>/net of each container's init process.
+// Containers sharing a network namespace are reported once.
+type SocketCollector struct {
+	lister  docker.ContainerLister
+	reader  SocketReader
+	filter  *docker.Filter
+	timeout time.Duration
+}
+
+// NewSocketCollector creates a socket collector. Containers are listed
+// through lister (the client itself or an Inventory).
+func NewSocketCollector(lister docker.ContainerLister, reader SocketReader, filter *docker.Filter, cfg *config.Config) *SocketCollector {
+	return &SocketCollector{
+		lister:  lister,
+		reader:  reader,
+		filter:  filter,
+		timeout: cfg.Collection.Timeout,
+	}
+}
+
+// Describe sends all socket metric descriptors.
+func (c *SocketCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range metrics.AllSocketDescs() {
+		ch <- d
+	}
+}
+
+// Collect reads the socket tables of every network namespace used by a
+// running container that passes the filter.
+func (c *SocketCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	var scrapeErrors int64
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	containers, err := c.lister.ListContainers(ctx)
+	if err != nil {
+		log.WithError(err).Error("Failed to list containers")
+		scrapeErrors++
+	}
+
+	for _, ctr := range c.namespaceOwners(containers) {
+		s, err := c.reader.Sockets(ctr.Pid)
+		if err != nil {
+			log.WithError(err).WithField("container", ctr.Name).Warn("Failed to read socket tables, skipping")
+			scrapeErrors++
+			continue
+		}
+
+		lv := docker.ExtractLabels(ctr).Values()
+		for _, state := range procfs.TCPStates {
+			if state == "" {
+				continue
+			}
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.NetworkTCPConnections, prometheus.GaugeValue, float64(s.TCP[state]), append(lv, state)...))
+		}
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.NetworkUDPSockets, prometheus.GaugeValue, float64(s.UDP), lv...))
+		for proto, counters := range s.Sockstat {
+			for kind, v := range counters {
+				metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.NetworkSockets, prometheus.GaugeValue, float64(v), append(lv, proto, kind)...))
+			}
+		}
+	}
+
+	duration := time.Since(start).Seconds()
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ExporterScrapeDuration, prometheus.GaugeValue, duration, "sockets"))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ExporterScrapeErrors, prometheus.CounterValue, float64(scrapeErrors), "sockets"))
+}
+
+// namespaceOwners picks one container per network namespace among the running
+// containers that pass the filter. The container that owns the namespace is
+// preferred over those that joined it with --network container:<id>, then
+// the first by name. Host-network containers are skipped: their sockets are
+// the host's.
+func (c *SocketCollector) namespaceOwners(containers []docker.Container) []*docker.Container {
+	owners := make(map[string]*docker.Container)
+	for i := range containers {
+		ctr := &containers[i]
+		if ctr.State != "running" || ctr.Pid == 0 || ctr.NetworkMode == "host" || !c.filter.Match(ctr) {
+			continue
+		}
+
+		key := c.namespaceKey(ctr)
+		if cur, ok := owners[key]; !ok || preferOwner(ctr, cur) {
+			owners[key] = ctr
+		}
+	}
+
+	out := make([]*docker.Container, 0, len(owners))
+	for _, ctr := range owners {
+		out = append(out, ctr)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// namespaceKey identifies a container's network namespace by inode. Reading
+// the namespace link needs the same privileges as ptrace; without them,
+// containers are grouped by the container whose namespace they joined.
+func (c *SocketCollector) namespaceKey(ctr *docker.Container) string {
+	if inode, err := c.reader.NetNS(ctr.Pid); err == nil {
+		return "netns:" + strconv.FormatUint(inode, 10)
+	}
+	if target, ok := strings.CutPrefix(ctr.NetworkMode, "container:"); ok {
+		return "container:" + target
+	}
+	return "container:" + ctr.ID
+}
+
+func preferOwner(a, b *docker.Container) bool {
+	aJoined := strings.HasPrefix(a.NetworkMode, "container:")
+	bJoined := strings.HasPrefix(b.NetworkMode, "container:")
+	if aJoined != bJoined {
+		return !aJoined
+	}
+	return a.Name < b.Name
+}
@@ -0,0 +1,94 @@
+package collector
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/internal/procfs"
+)
+
+type mockSocketReader struct {
+	netns   map[int]uint64
+	sockets map[int]*procfs.Sockets
+	reads   []int
+}
+
+func (m *mockSocketReader) NetNS(pid int) (uint64, error) {
+	if ino, ok := m.netns[pid]; ok {
+		return ino, nil
+	}
+	return 0, fmt.Errorf("readlink /proc/%d/ns/net: permission denied", pid)
+}
+
+func (m *mockSocketReader) Sockets(pid int) (*procfs.Sockets, error) {
+	m.reads = append(m.reads, pid)
+	if s, ok := m.sockets[pid]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("open /proc/%d/net/tcp: no such file or directory", pid)
+}
+
+func TestSocketCollector(t *testing.T) {
+	lister := &mockDockerClient{containers: []docker.Container{
+		{ID: "app", Name: "app", Image: "myapp", State: "running", Pid: 100, NetworkMode: "bridge"},
+		// Sidecar sharing app's namespace, listed first by name
+		{ID: "envoy", Name: "a-envoy", Image: "envoy", State: "running", Pid: 101, NetworkMode: "container:app"},
+		{ID: "host", Name: "node", Image: "node-exporter", State: "running", Pid: 102, NetworkMode: "host"},
+		{ID: "old", Name: "stopped", Image: "myapp", State: "exited", NetworkMode: "bridge"},
+		{ID: "gone", Name: "gone", Image: "myapp", State: "running", Pid: 103, NetworkMode: "bridge"},
+	}}
+	reader := &mockSocketReader{
+		netns: map[int]uint64{100: 4026532281, 101: 4026532281, 103: 4026532300},
+		sockets: map[int]*procfs.Sockets{
+			100: {
+				TCP:      map[string]uint64{"established": 3, "time_wait": 40},
+				UDP:      2,
+				Sockstat: map[string]map[string]uint64{"tcp": {"inuse": 3, "tw": 40}},
+			},
+		},
+	}
+
+	c := NewSocketCollector(lister, reader, newTestFilter(), newTestConfig())
+	collected := collectMetrics(c)
+
+	assert.ElementsMatch(t, []int{100, 103}, reader.reads, "one read per namespace, host network skipped")
+
+	states := map[string]float64{}
+	for _, m := range findMetric(collected, "container_network_tcp_connections") {
+		l := metricLabels(t, m)
+		assert.Equal(t, "app", l["container_name"], "the namespace owner reports it")
+		states[l["state"]] = gaugeValue(t, m)
+	}
+	assert.Len(t, states, 11, "every state is reported, zeros included")
+	assert.Equal(t, 40.0, states["time_wait"])
+	assert.Equal(t, 0.0, states["close_wait"])
+
+	udp := findMetric(collected, "container_network_udp_sockets")
+	require.Len(t, udp, 1)
+	assert.Equal(t, 2.0, gaugeValue(t, udp[0]))
+
+	sockstat := findMetric(collected, "container_network_sockets")
+	require.Len(t, sockstat, 2)
+
+	errs := findMetric(collected, "exporter_scrape_errors_total")
+	require.Len(t, errs, 1)
+	assert.Equal(t, 1.0, counterValue(t, errs[0]))
+}
+
+func TestSocketCollector_GroupsByNetworkModeWithoutNamespaceAccess(t *testing.T) {
+	lister := &mockDockerClient{containers: []docker.Container{
+		{ID: "app", Name: "app", Image: "myapp", State: "running", Pid: 100, NetworkMode: "bridge"},
+		{ID: "envoy", Name: "a-envoy", Image: "envoy", State: "running", Pid: 101, NetworkMode: "container:app"},
+	}}
+	reader := &mockSocketReader{sockets: map[int]*procfs.Sockets{
+		100: {TCP: map[string]uint64{}},
+		101: {TCP: map[string]uint64{}},
+	}}
+
+	collectMetrics(NewSocketCollector(lister, reader, newTestFilter(), newTestConfig()))
+	assert.Equal(t, []int{100}, reader.reads)
+}
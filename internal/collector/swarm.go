@@ -0,0 +1,115 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/internal/metrics"
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+// SwarmClient defines the Docker API methods needed by the swarm collector.
+type SwarmClient interface {
+	GetSwarmState(ctx context.Context) (*docker.SwarmState, error)
+}
+
+// SwarmCollector implements prometheus.Collector for swarm services, tasks
+// and nodes. It only produces data on a swarm manager.
+type SwarmCollector struct {
+	client  SwarmClient
+	timeout time.Duration
+}
+
+// NewSwarmCollector creates a new swarm metrics collector.
+func NewSwarmCollector(client SwarmClient, cfg *config.Config) *SwarmCollector {
+	return &SwarmCollector{
+		client:  client,
+		timeout: cfg.Collection.Timeout,
+	}
+}
+
+// Describe sends all swarm metric descriptors.
+func (c *SwarmCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range metrics.AllSwarmDescs() {
+		ch <- d
+	}
+}
+
+// Collect lists services, tasks and nodes and emits metrics.
+func (c *SwarmCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	var scrapeErrors int64
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	state, err := c.client.GetSwarmState(ctx)
+	if err != nil {
+		log.WithError(err).Error("Failed to get swarm state")
+		scrapeErrors++
+	} else {
+		c.emitServiceMetrics(ch, state)
+		c.emitNodeMetrics(ch, state.Nodes)
+	}
+
+	duration := time.Since(start).Seconds()
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ExporterScrapeDuration, prometheus.GaugeValue, duration, "swarm"))
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ExporterScrapeErrors, prometheus.CounterValue, float64(scrapeErrors), "swarm"))
+}
+
+func (c *SwarmCollector) emitServiceMetrics(ch chan<- prometheus.Metric, state *docker.SwarmState) {
+	// Task counts by service and state. Tasks of removed services are dropped.
+	taskStates := make(map[string]map[string]int, len(state.Services))
+	for _, svc := range state.Services {
+		taskStates[svc.ID] = make(map[string]int)
+	}
+	for _, t := range state.Tasks {
+		if counts, ok := taskStates[t.ServiceID]; ok {
+			counts[t.State]++
+		}
+	}
+
+	for i := range state.Services {
+		svc := &state.Services[i]
+		lv := docker.ExtractServiceLabels(svc).Values()
+
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SwarmServiceReplicasDesired, prometheus.GaugeValue, float64(svc.DesiredTasks), append(lv, svc.Mode)...))
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SwarmServiceReplicasRunning, prometheus.GaugeValue, float64(svc.RunningTasks), append(lv, svc.Mode)...))
+
+		counts := taskStates[svc.ID]
+		states := make([]string, 0, len(counts))
+		for s := range counts {
+			states = append(states, s)
+		}
+		sort.Strings(states)
+		for _, s := range states {
+			metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SwarmServiceTasks, prometheus.GaugeValue, float64(counts[s]), append(lv, s)...))
+		}
+
+		updateState := svc.UpdateState
+		if updateState == "" {
+			updateState = "none"
+		}
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SwarmServiceUpdateState, prometheus.GaugeValue, 1, append(lv, updateState)...))
+	}
+}
+
+func (c *SwarmCollector) emitNodeMetrics(ch chan<- prometheus.Metric, nodes []docker.Node) {
+	for _, n := range nodes {
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.SwarmNodeInfo, prometheus.GaugeValue, 1,
+			n.ID,
+			docker.SanitizeLabelValue(n.Hostname),
+			n.Role,
+			n.Availability,
+			n.State,
+			strconv.FormatBool(n.Leader),
+			docker.SanitizeLabelValue(n.EngineVersion),
+		))
+	}
+}
@@ -0,0 +1,126 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+)
+
+type mockSwarmClient struct {
+	state *docker.SwarmState
+	err   error
+}
+
+func (m *mockSwarmClient) GetSwarmState(_ context.Context) (*docker.SwarmState, error) {
+	return m.state, m.err
+}
+
+// metricLabels returns the label pairs of a metric as a map.
+func metricLabels(t *testing.T, m prometheus.Metric) map[string]string {
+	t.Helper()
+	d := &dto.Metric{}
+	require.NoError(t, m.Write(d))
+	labels := make(map[string]string, len(d.GetLabel()))
+	for _, lp := range d.GetLabel() {
+		labels[lp.GetName()] = lp.GetValue()
+	}
+	return labels
+}
+
+func TestSwarmCollector_Services(t *testing.T) {
+	mock := &mockSwarmClient{state: &docker.SwarmState{
+		Services: []docker.Service{
+			{
+				ID:           "svc1",
+				Name:         "shop_web",
+				Labels:       map[string]string{docker.LabelStackNamespace: "shop"},
+				Mode:         "replicated",
+				DesiredTasks: 3,
+				RunningTasks: 2,
+				UpdateState:  "updating",
+			},
+			{ID: "svc2", Name: "agent", Mode: "global", DesiredTasks: 2, RunningTasks: 2},
+		},
+		Tasks: []docker.Task{
+			{ID: "t1", ServiceID: "svc1", State: "running"},
+			{ID: "t2", ServiceID: "svc1", State: "running"},
+			{ID: "t3", ServiceID: "svc1", State: "failed"},
+			{ID: "t4", ServiceID: "svc2", State: "running"},
+			{ID: "t5", ServiceID: "svc2", State: "running"},
+			{ID: "t6", ServiceID: "gone", State: "shutdown"},
+		},
+	}}
+
+	collected := collectMetrics(NewSwarmCollector(mock, newTestConfig()))
+
+	desired := findMetric(collected, "swarm_service_replicas_desired")
+	require.Len(t, desired, 2)
+	assert.Equal(t, map[string]string{"service_name": "shop_web", "stack_namespace": "shop", "mode": "replicated"}, metricLabels(t, desired[0]))
+	assert.Equal(t, float64(3), gaugeValue(t, desired[0]))
+
+	running := findMetric(collected, "swarm_service_replicas_running")
+	require.Len(t, running, 2)
+	assert.Equal(t, float64(2), gaugeValue(t, running[0]))
+
+	// Task counts by state; the task of a removed service is dropped
+	tasks := findMetric(collected, "swarm_service_tasks")
+	counts := make(map[string]float64)
+	for _, m := range tasks {
+		l := metricLabels(t, m)
+		counts[l["service_name"]+"/"+l["state"]] = gaugeValue(t, m)
+	}
+	assert.Equal(t, map[string]float64{
+		"shop_web/failed":  1,
+		"shop_web/running": 2,
+		"agent/running":    2,
+	}, counts)
+
+	updates := findMetric(collected, "swarm_service_update_state")
+	require.Len(t, updates, 2)
+	assert.Equal(t, "updating", metricLabels(t, updates[0])["state"])
+	assert.Equal(t, "none", metricLabels(t, updates[1])["state"])
+}
+
+func TestSwarmCollector_Nodes(t *testing.T) {
+	mock := &mockSwarmClient{state: &docker.SwarmState{
+		Nodes: []docker.Node{
+			{ID: "n1", Hostname: "mgr-1", Role: "manager", Availability: "active", State: "ready", Leader: true, EngineVersion: "27.4.1"},
+			{ID: "n2", Hostname: "wrk-1", Role: "worker", Availability: "drain", State: "down", EngineVersion: "27.4.1"},
+		},
+	}}
+
+	collected := collectMetrics(NewSwarmCollector(mock, newTestConfig()))
+
+	nodes := findMetric(collected, "swarm_node_info")
+	require.Len(t, nodes, 2)
+	assert.Equal(t, map[string]string{
+		"node_id":        "n2",
+		"hostname":       "wrk-1",
+		"role":           "worker",
+		"availability":   "drain",
+		"state":          "down",
+		"leader":         "false",
+		"engine_version": "27.4.1",
+	}, metricLabels(t, nodes[1]))
+}
+
+func TestSwarmCollector_NotAManager(t *testing.T) {
+	mock := &mockSwarmClient{err: fmt.Errorf("This node is not a swarm manager")}
+
+	collected := collectMetrics(NewSwarmCollector(mock, newTestConfig()))
+
+	assert.Empty(t, findMetric(collected, "swarm_service_replicas_desired"))
+	errs := findMetric(collected, "exporter_scrape_errors_total")
+	require.Len(t, errs, 1)
+
+	d := &dto.Metric{}
+	require.NoError(t, errs[0].Write(d))
+	assert.Equal(t, float64(1), d.GetCounter().GetValue())
+}
@@ -66,6 +66,10 @@ func (c *SystemCollector) Collect(ch chan<- prometheus.Metric) {
 		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.DockerImagesTotal, prometheus.GaugeValue, float64(info.Images)))
 		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.DockerVolumesTotal, prometheus.GaugeValue, float64(info.Volumes)))
 		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.DockerNetworksTotal, prometheus.GaugeValue, float64(info.Networks)))
+
+		// Host totals, the denominators of the container host shares
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.DockerHostMemory, prometheus.GaugeValue, float64(info.MemTotal)))
+		metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.DockerHostCPUs, prometheus.GaugeValue, float64(info.NCPU)))
 	}
 
 	// Build info (always emitted)
@@ -4,21 +4,39 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
 	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+	log "github.com/sirupsen/logrus"
 )
 
 // Client wraps the Docker API client with timeout and convenience methods.
 type Client struct {
 	cli     *client.Client
 	timeout time.Duration
+
+	// Network ID -> driver, for Endpoint.Driver. Refreshed from the network
+	// list when an endpoint names a network not seen before; IDs the list
+	// didn't have map to "" until the next refresh.
+	driversMu sync.Mutex
+	drivers   map[string]string
+}
+
+// ContainerLister lists containers along with their inspect metadata. Both
+// Client and Inventory implement it.
+type ContainerLister interface {
+	ListContainers(ctx context.Context) ([]Container, error)
 }
 
 // NewClient creates a Docker client from configuration.
@@ -73,29 +91,121 @@ func (c *Client) ListContainers(ctx context.Context) ([]Container, error) {
 		// Fetch inspect data for health, restart count, exit code, started_at
 		inspect, err := c.cli.ContainerInspect(ctx, r.ID)
 		if err == nil {
-			ctr.RestartCount = inspect.RestartCount
-			ctr.ExitCode = inspect.State.ExitCode
-			if inspect.State.Health != nil {
-				ctr.Health = inspect.State.Health.Status
-			}
-			if inspect.State.StartedAt != "" {
-				if t, parseErr := time.Parse(time.RFC3339Nano, inspect.State.StartedAt); parseErr == nil {
-					ctr.StartedAt = t
-				}
-			}
+			applyInspect(&ctr, &inspect)
 		}
 
 		containers = append(containers, ctr)
 	}
+	c.resolveDrivers(ctx, containers)
+
+	return containers, nil
+}
+
+// ListContainerSizes returns all containers with the size of their writable
+// layer and root filesystem. Computing sizes means walking every container's
+// layers, which is slow on overlay2, so the call is bounded by ctx only, not
+// by the client timeout. No inspect data is fetched.
+func (c *Client) ListContainerSizes(ctx context.Context) ([]Container, error) {
+	raw, err := c.cli.ContainerList(ctx, containertypes.ListOptions{All: true, Size: true})
+	if err != nil {
+		return nil, fmt.Errorf("listing container sizes: %w", err)
+	}
+
+	containers := make([]Container, 0, len(raw))
+	for _, r := range raw {
+		name := ""
+		if len(r.Names) > 0 {
+			name = trimLeadingSlash(r.Names[0])
+		}
+
+		containers = append(containers, Container{
+			ID:         r.ID,
+			Name:       name,
+			Image:      r.Image,
+			Labels:     r.Labels,
+			Status:     r.Status,
+			State:      r.State,
+			SizeRw:     r.SizeRw,
+			SizeRootFs: r.SizeRootFs,
+		})
+	}
 
 	return containers, nil
 }
 
+// InspectContainer returns a single container built from inspect data alone.
+// Used by the inventory to refresh one container after an event.
+func (c *Client) InspectContainer(ctx context.Context, id string) (*Container, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	inspect, err := c.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("inspecting container %s: %w", id, err)
+	}
+
+	ctr := Container{
+		ID:   inspect.ID,
+		Name: trimLeadingSlash(inspect.Name),
+	}
+	if inspect.Config != nil {
+		ctr.Image = inspect.Config.Image
+		ctr.Labels = inspect.Config.Labels
+	}
+	if inspect.State != nil {
+		ctr.State = inspect.State.Status
+	}
+	applyInspect(&ctr, &inspect)
+	ctr.Status = describeStatus(&ctr, time.Now())
+	c.resolveDrivers(ctx, []Container{ctr})
+
+	return &ctr, nil
+}
+
+// Events subscribes to the given container events newer than since. The SDK
+// never closes the message channel: the stream ends with one error (io.EOF,
+// or ctx's error on cancellation) after which the error channel is closed,
+// so callers must stop at the first value received from it.
+func (c *Client) Events(ctx context.Context, since time.Time, actions ...events.Action) (<-chan events.Message, <-chan error) {
+	f := filters.NewArgs(filters.Arg("type", string(events.ContainerEventType)))
+	for _, action := range actions {
+		f.Add("event", string(action))
+	}
+
+	opts := events.ListOptions{Filters: f}
+	if !since.IsZero() {
+		opts.Since = strconv.FormatInt(since.Unix(), 10)
+	}
+
+	// No timeout here: the stream is long-lived and bounded by ctx.
+	return c.cli.Events(ctx, opts)
+}
+
 // GetContainerStats fetches a one-shot stats snapshot for a container.
 func (c *Client) GetContainerStats(ctx context.Context, id string) (*Stats, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
+	statsJSON, err := c.GetStatsJSON(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// We also need inspect data for full parsing
+	inspect, err := c.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("inspecting container %s: %w", id, err)
+	}
+
+	return ParseDockerStats(statsJSON, &inspect), nil
+}
+
+// GetStatsJSON fetches a one-shot stats snapshot without inspecting the
+// container, for callers that already hold its inspect data.
+func (c *Client) GetStatsJSON(ctx context.Context, id string) (*types.StatsJSON, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
 	// stream=false: returns a single JSON object and closes
 	resp, err := c.cli.ContainerStats(ctx, id, false)
 	if err != nil {
@@ -107,14 +217,131 @@ func (c *Client) GetContainerStats(ctx context.Context, id string) (*Stats, erro
 	if err := json.NewDecoder(resp.Body).Decode(&statsJSON); err != nil {
 		return nil, fmt.Errorf("decoding stats for %s: %w", id, err)
 	}
+	return &statsJSON, nil
+}
 
-	// We also need inspect data for full parsing
-	inspect, err := c.cli.ContainerInspect(ctx, id)
+// applyInspect copies the inspect-only fields onto a container.
+func applyInspect(ctr *Container, inspect *types.ContainerJSON) {
+	if inspect.ContainerJSONBase == nil || inspect.State == nil {
+		return
+	}
+	if inspect.HostConfig != nil {
+		ctr.CgroupParent = inspect.HostConfig.CgroupParent
+		ctr.NetworkMode = string(inspect.HostConfig.NetworkMode)
+		ctr.Limits = limitsFromHostConfig(inspect.HostConfig)
+	}
+	if inspect.NetworkSettings != nil {
+		ctr.Endpoints = endpointsFromInspect(inspect.NetworkSettings.Networks)
+	}
+	ctr.RestartCount = inspect.RestartCount
+	ctr.ExitCode = inspect.State.ExitCode
+	ctr.OOMKilled = inspect.State.OOMKilled
+	ctr.Pid = inspect.State.Pid
+	if inspect.State.Health != nil {
+		ctr.Health = inspect.State.Health.Status
+	}
+	if inspect.State.StartedAt != "" {
+		if t, parseErr := time.Parse(time.RFC3339Nano, inspect.State.StartedAt); parseErr == nil {
+			ctr.StartedAt = t
+		}
+	}
+	if inspect.State.FinishedAt != "" {
+		if t, parseErr := time.Parse(time.RFC3339Nano, inspect.State.FinishedAt); parseErr == nil {
+			ctr.FinishedAt = t
+		}
+	}
+}
+
+// endpointsFromInspect flattens inspect's network map, sorted by network name.
+func endpointsFromInspect(networks map[string]*network.EndpointSettings) []Endpoint {
+	if len(networks) == 0 {
+		return nil
+	}
+	endpoints := make([]Endpoint, 0, len(networks))
+	for name, es := range networks {
+		if es == nil {
+			continue
+		}
+		ep := Endpoint{
+			Network:     name,
+			NetworkID:   es.NetworkID,
+			MacAddress:  es.MacAddress,
+			IPAddress:   es.IPAddress,
+			IPPrefixLen: es.IPPrefixLen,
+		}
+		if ep.IPAddress == "" {
+			ep.IPAddress = es.GlobalIPv6Address
+			ep.IPPrefixLen = es.GlobalIPv6PrefixLen
+		}
+		endpoints = append(endpoints, ep)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].Network < endpoints[j].Network })
+	return endpoints
+}
+
+// resolveDrivers fills Endpoint.Driver. Networks keep their driver for life,
+// so the network list is only fetched again when an unknown ID shows up. On
+// failure drivers are left empty.
+func (c *Client) resolveDrivers(ctx context.Context, containers []Container) {
+	c.driversMu.Lock()
+	defer c.driversMu.Unlock()
+
+	for refreshed := false; ; refreshed = true {
+		missing := false
+		for i := range containers {
+			for j := range containers[i].Endpoints {
+				ep := &containers[i].Endpoints[j]
+				driver, ok := c.drivers[ep.NetworkID]
+				ep.Driver = driver
+				missing = missing || !ok
+			}
+		}
+		if !missing || refreshed {
+			return
+		}
+
+		// Replacing the map also drops removed networks
+		nets, err := c.cli.NetworkList(ctx, network.ListOptions{})
+		if err != nil {
+			log.WithError(err).Debug("Failed to list networks, network drivers unknown")
+			return
+		}
+		c.drivers = make(map[string]string, len(nets))
+		for _, n := range nets {
+			c.drivers[n.ID] = n.Driver
+		}
+
+		// Networks the list doesn't show (removed since, or swarm-scoped on
+		// a worker) are remembered as unknown, so they don't trigger a new
+		// listing on every call
+		for i := range containers {
+			for _, ep := range containers[i].Endpoints {
+				if _, ok := c.drivers[ep.NetworkID]; !ok {
+					c.drivers[ep.NetworkID] = ""
+				}
+			}
+		}
+	}
+}
+
+// OpenStatsStream opens a stream=true stats request. The daemon pushes a new
+// sample roughly every second until the container stops or ctx is canceled.
+func (c *Client) OpenStatsStream(ctx context.Context, id string) (*StatsStream, error) {
+	inspectCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	inspect, err := c.cli.ContainerInspect(inspectCtx, id)
 	if err != nil {
 		return nil, fmt.Errorf("inspecting container %s: %w", id, err)
 	}
 
-	return ParseDockerStats(&statsJSON, &inspect), nil
+	// No timeout on the stream itself: it is long-lived and bounded by ctx.
+	resp, err := c.cli.ContainerStats(ctx, id, true)
+	if err != nil {
+		return nil, fmt.Errorf("streaming stats for %s: %w", id, err)
+	}
+
+	return newStatsStream(resp.Body, &inspect), nil
 }
 
 // GetSystemInfo returns Docker daemon information.
@@ -153,6 +380,8 @@ func (c *Client) GetSystemInfo(ctx context.Context) (*SystemInfo, error) {
 		Volumes:           len(volResp.Volumes),
 		Networks:          len(netList),
 		ServerVersion:     info.ServerVersion,
+		MemTotal:          info.MemTotal,
+		NCPU:              info.NCPU,
 	}, nil
 }
 
@@ -0,0 +1,63 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// deviceNameTTL bounds how long a lookup is cached. Device numbers can be
+// reused after hot-unplug (nvme and dm minors are allocated dynamically).
+const deviceNameTTL = 10 * time.Minute
+
+// DeviceNames resolves block device numbers ("8:0") to kernel device names
+// ("sda", "nvme0n1", "dm-3") through /sys/dev/block, where each entry is a
+// symlink into the device tree ending in the device name. Lookups, failed
+// ones included, are cached. A nil *DeviceNames resolves nothing.
+type DeviceNames struct {
+	root string
+
+	mu    sync.Mutex
+	names map[string]deviceName
+}
+
+type deviceName struct {
+	name    string // "" when resolution failed
+	expires time.Time
+}
+
+// NewDeviceNames creates a resolver reading the /sys/dev/block directory at
+// root.
+func NewDeviceNames(root string) *DeviceNames {
+	return &DeviceNames{
+		root:  root,
+		names: make(map[string]deviceName),
+	}
+}
+
+// Name returns the kernel name of the device with the given "major:minor"
+// key, or the key itself if it can't be resolved.
+func (d *DeviceNames) Name(key string) string {
+	if d == nil {
+		return key
+	}
+
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.names[key]
+	if !ok || now.After(entry.expires) {
+		entry = deviceName{expires: now.Add(deviceNameTTL)}
+		if target, err := os.Readlink(filepath.Join(d.root, key)); err == nil {
+			entry.name = filepath.Base(target)
+		}
+		d.names[key] = entry
+	}
+
+	if entry.name == "" {
+		return key
+	}
+	return entry.name
+}
@@ -0,0 +1,28 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceNames(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Symlink("../../devices/pci0000:00/0000:00:1f.2/ata1/host0/target0:0:0/0:0:0:0/block/sda", filepath.Join(root, "8:0")))
+	require.NoError(t, os.Symlink("../../devices/virtual/block/dm-3", filepath.Join(root, "253:3")))
+
+	d := NewDeviceNames(root)
+	assert.Equal(t, "sda", d.Name("8:0"))
+	assert.Equal(t, "dm-3", d.Name("253:3"))
+	assert.Equal(t, "259:0", d.Name("259:0"), "unresolved devices fall back to the number")
+
+	// Cached: later changes are not seen until the entry expires
+	require.NoError(t, os.Symlink("../../devices/pci0000:00/nvme/nvme0/nvme0n1", filepath.Join(root, "259:0")))
+	assert.Equal(t, "259:0", d.Name("259:0"))
+
+	var none *DeviceNames
+	assert.Equal(t, "8:0", none.Name("8:0"))
+}
@@ -0,0 +1,122 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/docker/docker/api/types"
+)
+
+// DiskUsageCategory summarizes one kind of object in `docker system df`.
+type DiskUsageCategory struct {
+	Count       int
+	Active      int
+	Size        int64
+	Reclaimable int64
+}
+
+// VolumeUsage holds the size and reference count of one volume. Size is -1
+// for volumes whose driver doesn't report it (anything but "local").
+type VolumeUsage struct {
+	Name     string
+	Driver   string
+	Size     int64
+	RefCount int64
+}
+
+// DiskUsage is the daemon's disk usage, summarized the way `docker system df`
+// presents it.
+type DiskUsage struct {
+	Images     DiskUsageCategory
+	Containers DiskUsageCategory
+	Volumes    DiskUsageCategory
+	BuildCache DiskUsageCategory
+
+	VolumeDetails []VolumeUsage
+}
+
+// GetDiskUsage calls the daemon's disk-usage endpoint. It walks every layer
+// and volume and can take far longer than other calls, so it is bounded by
+// ctx only, not by the client timeout.
+func (c *Client) GetDiskUsage(ctx context.Context) (*DiskUsage, error) {
+	du, err := c.cli.DiskUsage(ctx, types.DiskUsageOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting disk usage: %w", err)
+	}
+	return summarizeDiskUsage(&du), nil
+}
+
+// summarizeDiskUsage computes totals and reclaimable space with the same rules
+// as the docker CLI: an image is reclaimable unless a container uses it, a
+// container's writable layer unless it is running, a volume unless it is
+// referenced, and a build cache record unless it is in use or shared.
+func summarizeDiskUsage(du *types.DiskUsage) *DiskUsage {
+	out := &DiskUsage{}
+
+	// Images: the total is the size of all layers on disk; layers shared with
+	// other images are only counted once.
+	out.Images.Size = du.LayersSize
+	var imagesInUse int64
+	for _, img := range du.Images {
+		out.Images.Count++
+		if img.Containers <= 0 {
+			continue
+		}
+		out.Images.Active++
+		if img.Size >= 0 && img.SharedSize >= 0 {
+			imagesInUse += img.Size - img.SharedSize
+		}
+	}
+	out.Images.Reclaimable = max(du.LayersSize-imagesInUse, 0)
+
+	for _, ctr := range du.Containers {
+		out.Containers.Count++
+		out.Containers.Size += ctr.SizeRw
+		if ctr.State == "running" {
+			out.Containers.Active++
+		} else {
+			out.Containers.Reclaimable += ctr.SizeRw
+		}
+	}
+
+	for _, vol := range du.Volumes {
+		out.Volumes.Count++
+		usage := VolumeUsage{Name: vol.Name, Driver: vol.Driver, Size: -1, RefCount: -1}
+		if vol.UsageData != nil {
+			usage.Size = vol.UsageData.Size
+			usage.RefCount = vol.UsageData.RefCount
+		}
+		out.VolumeDetails = append(out.VolumeDetails, usage)
+
+		if usage.RefCount > 0 {
+			out.Volumes.Active++
+		}
+		if usage.Size < 0 {
+			continue
+		}
+		out.Volumes.Size += usage.Size
+		if usage.RefCount == 0 {
+			out.Volumes.Reclaimable += usage.Size
+		}
+	}
+	sort.Slice(out.VolumeDetails, func(i, j int) bool {
+		return out.VolumeDetails[i].Name < out.VolumeDetails[j].Name
+	})
+
+	for _, bc := range du.BuildCache {
+		out.BuildCache.Count++
+		if bc.InUse {
+			out.BuildCache.Active++
+		}
+		if bc.Shared {
+			continue
+		}
+		out.BuildCache.Size += bc.Size
+		if !bc.InUse {
+			out.BuildCache.Reclaimable += bc.Size
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeDiskUsage(t *testing.T) {
+	du := &types.DiskUsage{
+		LayersSize: 1000,
+		Images: []*image.Summary{
+			{ID: "used", Size: 600, SharedSize: 100, Containers: 1},
+			{ID: "unused", Size: 300, SharedSize: 100, Containers: 0},
+		},
+		Containers: []*types.Container{
+			{ID: "a", State: "running", SizeRw: 40},
+			{ID: "b", State: "exited", SizeRw: 60},
+		},
+		Volumes: []*volume.Volume{
+			{Name: "pgdata", Driver: "local", UsageData: &volume.UsageData{Size: 500, RefCount: 1}},
+			{Name: "orphan", Driver: "local", UsageData: &volume.UsageData{Size: 200, RefCount: 0}},
+			{Name: "nfs", Driver: "nfs", UsageData: &volume.UsageData{Size: -1, RefCount: 2}},
+		},
+		BuildCache: []*types.BuildCache{
+			{ID: "c1", Size: 70, InUse: true},
+			{ID: "c2", Size: 30},
+			{ID: "c3", Size: 999, Shared: true},
+		},
+	}
+
+	got := summarizeDiskUsage(du)
+
+	// Images: everything but the used image's unique layers
+	assert.Equal(t, DiskUsageCategory{Count: 2, Active: 1, Size: 1000, Reclaimable: 500}, got.Images)
+	assert.Equal(t, DiskUsageCategory{Count: 2, Active: 1, Size: 100, Reclaimable: 60}, got.Containers)
+	// Volumes: drivers without size data count as objects but not bytes
+	assert.Equal(t, DiskUsageCategory{Count: 3, Active: 2, Size: 700, Reclaimable: 200}, got.Volumes)
+	// Build cache: shared records are excluded from both totals
+	assert.Equal(t, DiskUsageCategory{Count: 3, Active: 1, Size: 100, Reclaimable: 30}, got.BuildCache)
+
+	assert.Equal(t, []VolumeUsage{
+		{Name: "nfs", Driver: "nfs", Size: -1, RefCount: 2},
+		{Name: "orphan", Driver: "local", Size: 200, RefCount: 0},
+		{Name: "pgdata", Driver: "local", Size: 500, RefCount: 1},
+	}, got.VolumeDetails)
+}
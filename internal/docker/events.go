@@ -0,0 +1,236 @@
+package docker
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	log "github.com/sirupsen/logrus"
+)
+
+// trackedActions are the container events counted by EventTracker. Health
+// events arrive as "health_status: <status>" and are counted under
+// "health_status".
+var trackedActions = []events.Action{
+	events.ActionCreate,
+	events.ActionStart,
+	events.ActionDie,
+	events.ActionKill,
+	events.ActionRestart,
+	events.ActionOOM,
+	events.ActionHealthStatus,
+	events.ActionDestroy,
+}
+
+// eventSource is the subset of Client used by EventTracker.
+type eventSource interface {
+	Events(ctx context.Context, since time.Time, actions ...events.Action) (<-chan events.Message, <-chan error)
+}
+
+// EventCount is the number of events seen for one action and compose
+// service since the exporter started.
+type EventCount struct {
+	Action         string
+	ComposeService string
+	ComposeProject string
+	Count          uint64
+}
+
+// OOMCount is the number of OOM kills seen for one container since the
+// exporter started.
+type OOMCount struct {
+	Container Container // ID, Name, Image and Labels only
+	Count     uint64
+}
+
+// eventKey groups lifecycle events for counting.
+type eventKey struct {
+	action  string
+	service string
+	project string
+}
+
+// EventTracker follows the Docker events stream and keeps counters from it:
+// lifecycle events per action and compose service, and OOM kills per
+// container. OOM counts are keyed by container ID, so they survive restarts
+// and are dropped when the container is removed.
+//
+// After the stream drops, for instance across a daemon restart, it
+// resubscribes from the last event it saw (or from when it first subscribed),
+// so events in the gap are still counted.
+type EventTracker struct {
+	source eventSource
+	filter *Filter
+
+	mu      sync.Mutex
+	started time.Time // first subscription; the cursor until an event arrives
+	last    int64     // TimeNano of the newest event applied
+	actions map[eventKey]uint64
+	ooms    map[string]*OOMCount
+}
+
+// NewEventTracker creates a tracker backed by the given client. Events for
+// containers the filter rejects are ignored. Call Run to start following
+// events.
+func NewEventTracker(client *Client, filter *Filter) *EventTracker {
+	return newEventTracker(client, filter)
+}
+
+func newEventTracker(source eventSource, filter *Filter) *EventTracker {
+	return &EventTracker{
+		source:  source,
+		filter:  filter,
+		actions: make(map[eventKey]uint64),
+		ooms:    make(map[string]*OOMCount),
+	}
+}
+
+// Run follows the events stream until ctx is done.
+func (t *EventTracker) Run(ctx context.Context) {
+	backoff := inventoryMinBackoff
+
+	for {
+		streamCtx, cancel := context.WithCancel(ctx)
+		msgs, errs := t.source.Events(streamCtx, t.cursor(), trackedActions...)
+		err := t.follow(ctx, msgs, errs, &backoff)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		log.WithError(err).Warn("Docker events stream interrupted, resubscribing from last event")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, inventoryMaxBackoff)
+	}
+}
+
+// cursor returns the since time for the next subscription: zero (live events
+// only) the first time, then the newest event applied or, if none arrived
+// yet, the time of the first subscription.
+func (t *EventTracker) cursor() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case t.last > 0:
+		return time.Unix(0, t.last)
+	case !t.started.IsZero():
+		return t.started
+	default:
+		t.started = time.Now()
+		return time.Time{}
+	}
+}
+
+// follow applies events until the stream fails or ctx is done. The backoff
+// resets once the stream delivers anything.
+func (t *EventTracker) follow(ctx context.Context, msgs <-chan events.Message, errs <-chan error, backoff *time.Duration) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			return err
+		case msg := <-msgs:
+			*backoff = inventoryMinBackoff
+			t.handleEvent(msg)
+		}
+	}
+}
+
+func (t *EventTracker) handleEvent(msg events.Message) {
+	id := msg.Actor.ID
+	if id == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// The since filter has one-second granularity, so a resubscription
+	// replays events already applied.
+	if msg.TimeNano <= t.last {
+		return
+	}
+	t.last = msg.TimeNano
+
+	ctr := containerFromAttributes(id, msg.Actor.Attributes)
+	if t.filter != nil && !t.filter.Match(&ctr) {
+		return
+	}
+
+	action, _, _ := strings.Cut(string(msg.Action), ":")
+	t.actions[eventKey{
+		action:  action,
+		service: ctr.Labels[LabelComposeService],
+		project: ctr.Labels[LabelComposeProject],
+	}]++
+
+	switch events.Action(action) {
+	case events.ActionDestroy:
+		delete(t.ooms, id)
+	case events.ActionOOM:
+		c, ok := t.ooms[id]
+		if !ok {
+			c = &OOMCount{}
+			t.ooms[id] = c
+		}
+		// Refresh identity on every kill: the container may have been renamed
+		c.Container = ctr
+		c.Count++
+	}
+}
+
+// EventCounts returns a copy of the lifecycle event counts.
+func (t *EventTracker) EventCounts() []EventCount {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	counts := make([]EventCount, 0, len(t.actions))
+	for k, n := range t.actions {
+		counts = append(counts, EventCount{
+			Action:         k.action,
+			ComposeService: k.service,
+			ComposeProject: k.project,
+			Count:          n,
+		})
+	}
+	return counts
+}
+
+// OOMCounts returns a copy of the per-container OOM kill counts.
+func (t *EventTracker) OOMCounts() []OOMCount {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	counts := make([]OOMCount, 0, len(t.ooms))
+	for _, c := range t.ooms {
+		counts = append(counts, *c)
+	}
+	return counts
+}
+
+// containerFromAttributes rebuilds a container's identity from event
+// attributes, which carry its name, image and every container label.
+func containerFromAttributes(id string, attrs map[string]string) Container {
+	labels := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		switch k {
+		case "name", "image", "exitCode", "signal":
+		default:
+			labels[k] = v
+		}
+	}
+	return Container{
+		ID:     id,
+		Name:   attrs["name"],
+		Image:  attrs["image"],
+		Labels: labels,
+	}
+}
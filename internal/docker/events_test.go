@@ -0,0 +1,152 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+// fakeEventSource implements eventSource for testing, recording the since
+// cursor of each subscription.
+type fakeEventSource struct {
+	mu         sync.Mutex
+	msgs       chan events.Message
+	errs       chan error
+	since      []time.Time
+	subscribed chan struct{}
+}
+
+func (f *fakeEventSource) Events(_ context.Context, since time.Time, _ ...events.Action) (<-chan events.Message, <-chan error) {
+	f.mu.Lock()
+	f.msgs = make(chan events.Message)
+	f.errs = make(chan error, 1)
+	f.since = append(f.since, since)
+	msgs, errs := f.msgs, f.errs
+	f.mu.Unlock()
+	f.subscribed <- struct{}{}
+	return msgs, errs
+}
+
+func containerEvent(action events.Action, id string, at time.Time, attrs map[string]string) events.Message {
+	return events.Message{
+		Type:     events.ContainerEventType,
+		Action:   action,
+		Actor:    events.Actor{ID: id, Attributes: attrs},
+		TimeNano: at.UnixNano(),
+	}
+}
+
+func oomCountsByID(tr *EventTracker) map[string]OOMCount {
+	counts := map[string]OOMCount{}
+	for _, c := range tr.OOMCounts() {
+		counts[c.Container.ID] = c
+	}
+	return counts
+}
+
+func TestEventTracker_CountsOOMs(t *testing.T) {
+	tr := newEventTracker(nil, nil)
+	base := time.Unix(1700000000, 0)
+	attrs := map[string]string{
+		"name":                       "web",
+		"image":                      "nginx:latest",
+		"com.docker.compose.service": "web",
+	}
+
+	tr.handleEvent(containerEvent(events.ActionOOM, "a", base, attrs))
+	tr.handleEvent(containerEvent(events.ActionOOM, "a", base.Add(time.Second), attrs))
+	tr.handleEvent(containerEvent(events.ActionOOM, "b", base.Add(2*time.Second), map[string]string{"name": "db"}))
+
+	// Replayed after a resubscription: already applied
+	tr.handleEvent(containerEvent(events.ActionOOM, "a", base.Add(time.Second), attrs))
+
+	counts := oomCountsByID(tr)
+	require.Len(t, counts, 2)
+	assert.Equal(t, uint64(2), counts["a"].Count)
+	assert.Equal(t, "web", counts["a"].Container.Name)
+	assert.Equal(t, "nginx:latest", counts["a"].Container.Image)
+	assert.Equal(t, map[string]string{"com.docker.compose.service": "web"}, counts["a"].Container.Labels)
+	assert.Equal(t, uint64(1), counts["b"].Count)
+
+	tr.handleEvent(containerEvent(events.ActionDestroy, "a", base.Add(3*time.Second), attrs))
+	counts = oomCountsByID(tr)
+	assert.NotContains(t, counts, "a")
+	assert.Contains(t, counts, "b")
+}
+
+func TestEventTracker_CountsActionsPerService(t *testing.T) {
+	filter, err := NewFilter(config.FiltersConfig{
+		Exclude: config.FilterSet{Names: []string{"^test-"}},
+	})
+	require.NoError(t, err)
+
+	tr := newEventTracker(nil, filter)
+	base := time.Unix(1700000000, 0)
+	web := map[string]string{
+		"name":              "shop-web-1",
+		LabelComposeService: "web",
+		LabelComposeProject: "shop",
+	}
+
+	tr.handleEvent(containerEvent(events.ActionStart, "a", base, web))
+	tr.handleEvent(containerEvent(events.ActionDie, "a", base.Add(1), map[string]string{"name": "shop-web-1", "exitCode": "1", LabelComposeService: "web", LabelComposeProject: "shop"}))
+	tr.handleEvent(containerEvent(events.ActionStart, "a", base.Add(2), web))
+	tr.handleEvent(containerEvent("health_status: unhealthy", "a", base.Add(3), web))
+	// A recreated container counts towards the same service
+	tr.handleEvent(containerEvent(events.ActionStart, "b", base.Add(4), web))
+	tr.handleEvent(containerEvent(events.ActionStart, "c", base.Add(5), map[string]string{"name": "test-runner"}))
+	tr.handleEvent(containerEvent(events.ActionStart, "d", base.Add(6), map[string]string{"name": "adhoc"}))
+
+	got := map[string]uint64{}
+	for _, e := range tr.EventCounts() {
+		got[e.Action+"/"+e.ComposeProject+"/"+e.ComposeService] = e.Count
+	}
+	assert.Equal(t, map[string]uint64{
+		"start/shop/web":         3,
+		"die/shop/web":           1,
+		"health_status/shop/web": 1,
+		"start//":                1,
+	}, got)
+}
+
+func TestEventTracker_ResubscribesFromCursor(t *testing.T) {
+	src := &fakeEventSource{subscribed: make(chan struct{}, 10)}
+	tr := newEventTracker(src, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tr.Run(ctx)
+	<-src.subscribed
+
+	// Dropped before any event: resume from the first subscription
+	src.errs <- fmt.Errorf("unexpected EOF")
+	select {
+	case <-src.subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tracker did not resubscribe")
+	}
+
+	at := time.Unix(1700000000, 500)
+	src.msgs <- containerEvent(events.ActionOOM, "a", at, map[string]string{"name": "web"})
+	src.errs <- fmt.Errorf("unexpected EOF")
+	select {
+	case <-src.subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tracker did not resubscribe")
+	}
+
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	require.Len(t, src.since, 3)
+	assert.True(t, src.since[0].IsZero(), "first subscription starts from now")
+	assert.False(t, src.since[1].IsZero(), "no events yet: resume from the first subscription")
+	assert.True(t, src.since[2].Equal(at), "resume from the last event")
+}
@@ -81,6 +81,40 @@ func (f *Filter) Match(c *Container) bool {
 	return false
 }
 
+// ImageFilter decides whether an image is reported by the image collector,
+// matching its "repository:tag" reference against allow and deny patterns.
+// Deny patterns take precedence. Images pulled by digest only match on their
+// repository, and dangling images have an empty reference.
+type ImageFilter struct {
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+}
+
+// NewImageFilter compiles image patterns from configuration. Returns an error
+// if any regex pattern is invalid.
+func NewImageFilter(cfg config.ImagesConfig) (*ImageFilter, error) {
+	f := &ImageFilter{}
+
+	var err error
+	if f.allow, err = compilePatterns(cfg.Allow); err != nil {
+		return nil, fmt.Errorf("compiling image allow patterns: %w", err)
+	}
+	if f.deny, err = compilePatterns(cfg.Deny); err != nil {
+		return nil, fmt.Errorf("compiling image deny patterns: %w", err)
+	}
+
+	return f, nil
+}
+
+// Match returns true if the image should be reported.
+func (f *ImageFilter) Match(img *Image) bool {
+	ref := img.Reference()
+	if matchesAny(ref, f.deny) {
+		return false
+	}
+	return len(f.allow) == 0 || matchesAny(ref, f.allow)
+}
+
 func parseLabels(raw []string) map[string]string {
 	labels := make(map[string]string, len(raw))
 	for _, l := range raw {
@@ -119,3 +119,29 @@ func TestFilter_InvalidRegex(t *testing.T) {
 	})
 	assert.Error(t, err)
 }
+
+func TestImageFilter_AllowDeny(t *testing.T) {
+	f, err := NewImageFilter(config.ImagesConfig{
+		Allow: []string{"^registry.example.com/"},
+		Deny:  []string{":dev-"},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, f.Match(&Image{Repository: "registry.example.com/shop/web", Tag: "1.4.2"}))
+	assert.False(t, f.Match(&Image{Repository: "registry.example.com/shop/web", Tag: "dev-abc123"}), "deny wins")
+	assert.False(t, f.Match(&Image{Repository: "nginx", Tag: "latest"}), "not allowed")
+	assert.False(t, f.Match(&Image{Dangling: true}), "dangling images have an empty reference")
+}
+
+func TestImageFilter_NoRules(t *testing.T) {
+	f, err := NewImageFilter(config.ImagesConfig{})
+	require.NoError(t, err)
+
+	assert.True(t, f.Match(&Image{Repository: "nginx", Tag: "latest"}))
+	assert.True(t, f.Match(&Image{Dangling: true}))
+}
+
+func TestImageFilter_InvalidRegex(t *testing.T) {
+	_, err := NewImageFilter(config.ImagesConfig{Deny: []string{"[invalid"}})
+	assert.Error(t, err)
+}
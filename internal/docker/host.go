@@ -0,0 +1,85 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// hostInfoRetry spaces out lookups while the daemon doesn't answer, so an
+// unreachable daemon costs one failed call per interval rather than one per
+// scrape.
+const hostInfoRetry = 30 * time.Second
+
+// HostInfo holds the daemon host's resource totals.
+type HostInfo struct {
+	MemTotal int64
+	NCPU     int
+}
+
+// HostInfoGetter is the subset of Client used to look up host resources.
+type HostInfoGetter interface {
+	GetHostInfo(ctx context.Context) (*HostInfo, error)
+}
+
+// GetHostInfo returns the host's total memory and CPU count. Unlike
+// GetSystemInfo it makes a single info call, without listing images,
+// volumes and networks.
+func (c *Client) GetHostInfo(ctx context.Context) (*HostInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	info, err := c.cli.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting system info: %w", err)
+	}
+	return &HostInfo{MemTotal: info.MemTotal, NCPU: info.NCPU}, nil
+}
+
+// HostResources is the daemon host's total memory and CPU count (Info.MemTotal
+// and Info.NCPU), looked up until the daemon answers once and cached after
+// that. A nil *HostResources knows nothing.
+type HostResources struct {
+	info HostInfoGetter
+
+	mu        sync.Mutex
+	memTotal  uint64
+	cpus      int
+	lastTried time.Time
+}
+
+// NewHostResources creates a lookup backed by the given client.
+func NewHostResources(info HostInfoGetter) *HostResources {
+	return &HostResources{info: info}
+}
+
+// Get returns the host's total memory in bytes and its CPU count, or zeros
+// while the daemon is unreachable. The lookup runs outside the lock, and at
+// most once per hostInfoRetry until it succeeds, so concurrent scrapes never
+// queue behind a slow daemon.
+func (h *HostResources) Get(ctx context.Context) (memTotal uint64, cpus int) {
+	if h == nil {
+		return 0, 0
+	}
+
+	now := time.Now()
+	h.mu.Lock()
+	if h.memTotal > 0 || now.Sub(h.lastTried) < hostInfoRetry {
+		defer h.mu.Unlock()
+		return h.memTotal, h.cpus
+	}
+	h.lastTried = now
+	h.mu.Unlock()
+
+	info, err := h.info.GetHostInfo(ctx)
+	if err != nil || info.MemTotal <= 0 {
+		return 0, 0
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.memTotal = uint64(info.MemTotal)
+	h.cpus = info.NCPU
+	return h.memTotal, h.cpus
+}
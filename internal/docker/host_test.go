@@ -0,0 +1,56 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeHostInfo struct {
+	memTotal int64
+	ncpu     int
+	err      error
+	calls    int
+}
+
+func (f *fakeHostInfo) GetHostInfo(_ context.Context) (*HostInfo, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &HostInfo{MemTotal: f.memTotal, NCPU: f.ncpu}, nil
+}
+
+func TestHostResources(t *testing.T) {
+	ctx := context.Background()
+	info := &fakeHostInfo{err: fmt.Errorf("connection refused")}
+	h := NewHostResources(info)
+
+	mem, cpus := h.Get(ctx)
+	assert.Zero(t, mem, "daemon unreachable")
+	assert.Zero(t, cpus)
+
+	// Failures aren't retried on every call
+	info.err = nil
+	info.memTotal = 8 << 30
+	info.ncpu = 4
+	mem, _ = h.Get(ctx)
+	assert.Zero(t, mem)
+	assert.Equal(t, 1, info.calls, "retry waits for hostInfoRetry")
+
+	h.lastTried = time.Now().Add(-hostInfoRetry)
+	for i := 0; i < 2; i++ {
+		mem, cpus = h.Get(ctx)
+		assert.Equal(t, uint64(8<<30), mem)
+		assert.Equal(t, 4, cpus)
+	}
+	assert.Equal(t, 2, info.calls, "cached once the daemon answered")
+
+	var none *HostResources
+	mem, cpus = none.Get(ctx)
+	assert.Zero(t, mem)
+	assert.Zero(t, cpus)
+}
@@ -0,0 +1,106 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+)
+
+// Image holds per-image details from an image list call.
+type Image struct {
+	ID         string
+	Repository string // empty for dangling images
+	Tag        string // empty for dangling and digest-only images
+	Size       int64
+	SharedSize int64 // -1 when the daemon doesn't compute it (API < 1.42)
+	Created    time.Time
+	Containers int // containers, running or not, created from the image
+	Dangling   bool
+}
+
+// Reference returns "repository:tag", the bare repository for an image
+// pulled by digest only, or "" for a dangling image.
+func (i *Image) Reference() string {
+	if i.Repository == "" || i.Tag == "" {
+		return i.Repository
+	}
+	return i.Repository + ":" + i.Tag
+}
+
+// ListImages returns the top-level images with their sizes and the number of
+// containers using each. An image with several tags is reported once, under
+// its first tag in sorted order.
+func (c *Client) ListImages(ctx context.Context) ([]Image, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	summaries, err := c.cli.ImageList(ctx, image.ListOptions{SharedSize: true})
+	if err != nil {
+		return nil, fmt.Errorf("listing images: %w", err)
+	}
+
+	// The daemon only counts containers per image on request in newer API
+	// versions, so count them from the container list instead.
+	containers, err := c.cli.ContainerList(ctx, containertypes.ListOptions{All: true})
+	if err != nil {
+		return nil, fmt.Errorf("listing containers: %w", err)
+	}
+	usage := make(map[string]int, len(summaries))
+	for _, ctr := range containers {
+		usage[ctr.ImageID]++
+	}
+
+	images := make([]Image, 0, len(summaries))
+	for _, s := range summaries {
+		img := Image{
+			ID:         s.ID,
+			Size:       s.Size,
+			SharedSize: s.SharedSize,
+			Created:    time.Unix(s.Created, 0),
+			Containers: usage[s.ID],
+		}
+		img.Repository, img.Tag, img.Dangling = primaryTag(s.RepoTags, s.RepoDigests)
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+// primaryTag splits the first real tag of an image into repository and tag.
+// An image pulled by digest only has no tag, and its repository is taken
+// from its first digest. As with "docker images -f dangling=true", only
+// images with neither ("<none>:<none>", "<none>@<none>") are dangling.
+func primaryTag(repoTags, repoDigests []string) (repository, tag string, dangling bool) {
+	tags := realRefs(repoTags, "<none>:<none>")
+	if len(tags) == 0 {
+		digests := realRefs(repoDigests, "<none>@<none>")
+		if len(digests) == 0 {
+			return "", "", true
+		}
+		repository, _, _ = strings.Cut(digests[0], "@")
+		return repository, "", false
+	}
+
+	// Split at the last colon, after any registry port
+	ref := tags[0]
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:], false
+	}
+	return ref, "latest", false
+}
+
+// realRefs returns refs without the placeholder, sorted.
+func realRefs(refs []string, none string) []string {
+	kept := make([]string, 0, len(refs))
+	for _, r := range refs {
+		if r != none {
+			kept = append(kept, r)
+		}
+	}
+	sort.Strings(kept)
+	return kept
+}
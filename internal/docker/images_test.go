@@ -0,0 +1,38 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrimaryTag(t *testing.T) {
+	tests := []struct {
+		name        string
+		repoTags    []string
+		repoDigests []string
+		repo        string
+		tag         string
+		dangling    bool
+	}{
+		{"single tag", []string{"nginx:1.27"}, nil, "nginx", "1.27", false},
+		{"first sorted tag", []string{"app:latest", "app:1.0"}, nil, "app", "1.0", false},
+		{"registry with port", []string{"localhost:5000/app:v2"}, nil, "localhost:5000/app", "v2", false},
+		{"registry port without tag", []string{"localhost:5000/app"}, nil, "localhost:5000/app", "latest", false},
+		{"tag preferred over digest", []string{"nginx:1.27"}, []string{"nginx@sha256:aaaa"}, "nginx", "1.27", false},
+		{"digest only", nil, []string{"nginx@sha256:aaaa"}, "nginx", "", false},
+		{"digest only with none tag", []string{"<none>:<none>"}, []string{"localhost:5000/app@sha256:bbbb"}, "localhost:5000/app", "", false},
+		{"first sorted digest", nil, []string{"web@sha256:cccc", "app@sha256:dddd"}, "app", "", false},
+		{"none tag", []string{"<none>:<none>"}, nil, "", "", true},
+		{"none tag and digest", []string{"<none>:<none>"}, []string{"<none>@<none>"}, "", "", true},
+		{"no tags", nil, nil, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, tag, dangling := primaryTag(tt.repoTags, tt.repoDigests)
+			assert.Equal(t, tt.repo, repo)
+			assert.Equal(t, tt.tag, tag)
+			assert.Equal(t, tt.dangling, dangling)
+		})
+	}
+}
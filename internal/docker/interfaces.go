@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"net"
+	"strings"
+)
+
+// InterfaceEndpoints maps the container's interfaces, as named in its stats,
+// to the network endpoints they belong to. Inspect doesn't name interfaces,
+// so they are matched through what the container's /proc shows of them: an
+// interface whose MAC address (macs) is an endpoint's belongs to it, and so
+// does one with a directly connected subnet (subnets, from the IPv4 and IPv6
+// routing tables) holding an endpoint's IP.
+//
+// Without either, only eth0 is resolved: a container on one network has it
+// there, and the network a container was created with (its NetworkMode) is
+// joined first, as eth0. Other interfaces are left out.
+func InterfaceEndpoints(ctr *Container, ifaces []string, macs map[string]string, subnets map[string][]*net.IPNet) map[string]Endpoint {
+	out := make(map[string]Endpoint)
+	if len(ctr.Endpoints) == 0 {
+		return out
+	}
+
+	for _, iface := range ifaces {
+		if mac := macs[iface]; mac != "" {
+			for _, ep := range ctr.Endpoints {
+				if strings.EqualFold(ep.MacAddress, mac) {
+					out[iface] = ep
+				}
+			}
+			if _, ok := out[iface]; ok {
+				continue
+			}
+		}
+		for _, subnet := range subnets[iface] {
+			for _, ep := range ctr.Endpoints {
+				if ip := net.ParseIP(ep.IPAddress); ip != nil && subnet.Contains(ip) {
+					out[iface] = ep
+				}
+			}
+		}
+	}
+	if len(out) > 0 {
+		return out
+	}
+
+	if len(ctr.Endpoints) == 1 && len(ifaces) == 1 {
+		out[ifaces[0]] = ctr.Endpoints[0]
+		return out
+	}
+	primary := ctr.NetworkMode
+	if primary == "default" {
+		primary = "bridge"
+	}
+	for _, iface := range ifaces {
+		if iface != "eth0" {
+			continue
+		}
+		for _, ep := range ctr.Endpoints {
+			if ep.Network == primary || ep.NetworkID == primary {
+				out[iface] = ep
+			}
+		}
+	}
+	return out
+}
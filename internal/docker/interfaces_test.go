@@ -0,0 +1,131 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+func mustCIDR(t *testing.T, s string) *net.IPNet {
+	t.Helper()
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestInterfaceEndpoints(t *testing.T) {
+	frontend := Endpoint{Network: "app_frontend", NetworkID: "f1", MacAddress: "02:42:ac:14:00:05", IPAddress: "172.20.0.5", IPPrefixLen: 24}
+	backend := Endpoint{Network: "app_backend", NetworkID: "b1", MacAddress: "02:42:ac:15:00:07", IPAddress: "172.21.0.7", IPPrefixLen: 24}
+	ctr := &Container{NetworkMode: "app_frontend", Endpoints: []Endpoint{backend, frontend}}
+
+	t.Run("MAC address", func(t *testing.T) {
+		got := InterfaceEndpoints(ctr, []string{"eth0", "eth1"}, map[string]string{
+			"eth0": "02:42:AC:15:00:07",
+			"eth1": "02:42:ac:14:00:05",
+		}, nil)
+		assert.Equal(t, map[string]Endpoint{"eth0": backend, "eth1": frontend}, got)
+	})
+
+	t.Run("routing table", func(t *testing.T) {
+		got := InterfaceEndpoints(ctr, []string{"eth0", "eth1"}, nil, map[string][]*net.IPNet{
+			"eth0": {mustCIDR(t, "172.21.0.0/24")},
+			"eth1": {mustCIDR(t, "172.20.0.0/24")},
+		})
+		assert.Equal(t, map[string]Endpoint{"eth0": backend, "eth1": frontend}, got)
+	})
+
+	t.Run("IPv6-only network", func(t *testing.T) {
+		v6 := Endpoint{Network: "app_v6", NetworkID: "v1", IPAddress: "fd00:0:0:2::3", IPPrefixLen: 64}
+		dual := &Container{NetworkMode: "app_frontend", Endpoints: []Endpoint{frontend, v6}}
+		got := InterfaceEndpoints(dual, []string{"eth0", "eth1"}, nil, map[string][]*net.IPNet{
+			"eth0": {mustCIDR(t, "172.20.0.0/24")},
+			"eth1": {mustCIDR(t, "fd00:0:0:2::/64")},
+		})
+		assert.Equal(t, map[string]Endpoint{"eth0": frontend, "eth1": v6}, got)
+	})
+
+	t.Run("MAC address without match falls back to routes", func(t *testing.T) {
+		got := InterfaceEndpoints(ctr, []string{"eth0", "eth1"},
+			map[string]string{"eth0": "02:42:00:00:00:01", "eth1": "02:42:00:00:00:02"},
+			map[string][]*net.IPNet{"eth1": {mustCIDR(t, "172.20.0.0/24")}})
+		assert.Equal(t, map[string]Endpoint{"eth1": frontend}, got)
+	})
+
+	t.Run("without proc", func(t *testing.T) {
+		got := InterfaceEndpoints(ctr, []string{"eth0", "eth1"}, nil, nil)
+		assert.Equal(t, map[string]Endpoint{"eth0": frontend}, got, "only the primary network's interface is known")
+	})
+
+	t.Run("single network", func(t *testing.T) {
+		single := &Container{NetworkMode: "default", Endpoints: []Endpoint{{Network: "bridge", IPAddress: "172.17.0.2"}}}
+		got := InterfaceEndpoints(single, []string{"eth0"}, nil, nil)
+		assert.Equal(t, "bridge", got["eth0"].Network)
+	})
+
+	t.Run("no networks", func(t *testing.T) {
+		assert.Empty(t, InterfaceEndpoints(&Container{NetworkMode: "none"}, []string{"eth0"}, nil, nil))
+	})
+}
+
+func TestEndpointsFromInspect(t *testing.T) {
+	got := endpointsFromInspect(map[string]*network.EndpointSettings{
+		"web":  {NetworkID: "w1", MacAddress: "02:42:ac:14:00:05", IPAddress: "172.20.0.5", IPPrefixLen: 24},
+		"v6":   {NetworkID: "v1", GlobalIPv6Address: "fd00::5", GlobalIPv6PrefixLen: 64},
+		"gone": nil,
+	})
+	assert.Equal(t, []Endpoint{
+		{Network: "v6", NetworkID: "v1", IPAddress: "fd00::5", IPPrefixLen: 64},
+		{Network: "web", NetworkID: "w1", MacAddress: "02:42:ac:14:00:05", IPAddress: "172.20.0.5", IPPrefixLen: 24},
+	}, got)
+	assert.Nil(t, endpointsFromInspect(nil))
+}
+
+func TestResolveDrivers_CachesUnknownNetworks(t *testing.T) {
+	var lists atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Api-Version", "1.45")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_ping"):
+			_, _ = io.WriteString(w, "OK")
+		case strings.HasSuffix(r.URL.Path, "/networks"):
+			lists.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `[{"Id":"net1","Name":"backend","Driver":"bridge"}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(config.DockerConfig{Host: "tcp://" + srv.Listener.Addr().String()}, time.Second)
+	require.NoError(t, err)
+	defer c.Close()
+
+	// gone is a network removed between the inspect and the listing
+	containers := []Container{{Endpoints: []Endpoint{{NetworkID: "net1"}, {NetworkID: "gone"}}}}
+	for i := 0; i < 3; i++ {
+		c.resolveDrivers(context.Background(), containers)
+		assert.Equal(t, "bridge", containers[0].Endpoints[0].Driver)
+		assert.Empty(t, containers[0].Endpoints[1].Driver)
+	}
+	assert.Equal(t, int32(1), lists.Load(), "a network missing from the list must not relist on every call")
+
+	// A network not seen before still triggers a refresh
+	containers[0].Endpoints = append(containers[0].Endpoints, Endpoint{NetworkID: "new"})
+	c.resolveDrivers(context.Background(), containers)
+	assert.Equal(t, int32(2), lists.Load())
+}
@@ -0,0 +1,241 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/errdefs"
+	units "github.com/docker/go-units"
+	log "github.com/sirupsen/logrus"
+)
+
+// inventoryActions are the container events that can change anything the
+// exporter reports about a container.
+var inventoryActions = []events.Action{
+	events.ActionCreate,
+	events.ActionStart,
+	events.ActionDie,
+	events.ActionDestroy,
+	events.ActionRename,
+	events.ActionUpdate,
+	events.ActionPause,
+	events.ActionUnPause,
+	events.ActionHealthStatus,
+}
+
+const (
+	inventoryMinBackoff = 1 * time.Second
+	inventoryMaxBackoff = 30 * time.Second
+)
+
+// inventorySource is the subset of Client used by Inventory.
+type inventorySource interface {
+	ListContainers(ctx context.Context) ([]Container, error)
+	InspectContainer(ctx context.Context, id string) (*Container, error)
+	GetContainerStats(ctx context.Context, id string) (*Stats, error)
+	GetStatsJSON(ctx context.Context, id string) (*types.StatsJSON, error)
+	Events(ctx context.Context, since time.Time, actions ...events.Action) (<-chan events.Message, <-chan error)
+}
+
+// Inventory keeps an in-memory view of all containers. It lists and inspects
+// once, then follows the Docker events stream so scrapes never have to call
+// ContainerList/ContainerInspect. After the stream drops it resubscribes and
+// does a full resync, since events may have been missed in between.
+type Inventory struct {
+	source inventorySource
+
+	mu         sync.RWMutex
+	containers map[string]Container
+	synced     bool
+}
+
+// NewInventory creates an inventory backed by the given client. Call Run to
+// start following events; until the first sync completes, ListContainers
+// falls through to the client.
+func NewInventory(client *Client) *Inventory {
+	return newInventory(client)
+}
+
+func newInventory(source inventorySource) *Inventory {
+	return &Inventory{
+		source:     source,
+		containers: make(map[string]Container),
+	}
+}
+
+// Run syncs the inventory and follows the events stream until ctx is done.
+func (inv *Inventory) Run(ctx context.Context) {
+	backoff := inventoryMinBackoff
+
+	for {
+		// Subscribe before listing so nothing that happens during the resync
+		// is lost. Replaying an event on top of fresh data is harmless since
+		// every event triggers a re-inspect.
+		streamCtx, cancel := context.WithCancel(ctx)
+		msgs, errs := inv.source.Events(streamCtx, time.Time{}, inventoryActions...)
+
+		if err := inv.resync(ctx); err != nil {
+			log.WithError(err).Warn("Container inventory resync failed")
+		} else {
+			backoff = inventoryMinBackoff
+			err = inv.follow(ctx, msgs, errs)
+			if ctx.Err() == nil {
+				log.WithError(err).Warn("Docker events stream interrupted, resubscribing")
+			}
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, inventoryMaxBackoff)
+	}
+}
+
+// follow applies events until the stream fails or ctx is done.
+func (inv *Inventory) follow(ctx context.Context, msgs <-chan events.Message, errs <-chan error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			return err
+		case msg := <-msgs:
+			inv.handleEvent(ctx, msg)
+		}
+	}
+}
+
+// resync replaces the whole inventory with a fresh list.
+func (inv *Inventory) resync(ctx context.Context) error {
+	containers, err := inv.source.ListContainers(ctx)
+	if err != nil {
+		return fmt.Errorf("resyncing inventory: %w", err)
+	}
+
+	fresh := make(map[string]Container, len(containers))
+	for _, c := range containers {
+		fresh[c.ID] = c
+	}
+
+	inv.mu.Lock()
+	inv.containers = fresh
+	inv.synced = true
+	inv.mu.Unlock()
+
+	log.WithField("containers", len(fresh)).Debug("Container inventory synced")
+	return nil
+}
+
+func (inv *Inventory) handleEvent(ctx context.Context, msg events.Message) {
+	id := msg.Actor.ID
+	if id == "" {
+		return
+	}
+
+	if msg.Action == events.ActionDestroy {
+		inv.remove(id)
+		return
+	}
+
+	ctr, err := inv.source.InspectContainer(ctx, id)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			inv.remove(id)
+			return
+		}
+		log.WithError(err).WithField("container", id).Warn("Failed to refresh container after event")
+		return
+	}
+
+	inv.mu.Lock()
+	inv.containers[id] = *ctr
+	inv.mu.Unlock()
+}
+
+func (inv *Inventory) remove(id string) {
+	inv.mu.Lock()
+	delete(inv.containers, id)
+	inv.mu.Unlock()
+}
+
+// ListContainers returns a copy of the current inventory.
+func (inv *Inventory) ListContainers(ctx context.Context) ([]Container, error) {
+	inv.mu.RLock()
+	if !inv.synced {
+		inv.mu.RUnlock()
+		return inv.source.ListContainers(ctx)
+	}
+
+	now := time.Now()
+	containers := make([]Container, 0, len(inv.containers))
+	for _, c := range inv.containers {
+		// The human-readable status embeds a duration, so it goes stale
+		// between events and has to be rebuilt on every read.
+		c.Status = describeStatus(&c, now)
+		containers = append(containers, c)
+	}
+	inv.mu.RUnlock()
+
+	return containers, nil
+}
+
+// GetContainerStats fetches stats and takes container identity from the
+// inventory, so scrapes make no inspect call. Containers the inventory
+// doesn't hold, as before the first sync, go through the client's
+// stats-plus-inspect path.
+func (inv *Inventory) GetContainerStats(ctx context.Context, id string) (*Stats, error) {
+	inv.mu.RLock()
+	ctr, ok := inv.containers[id]
+	inv.mu.RUnlock()
+	if !ok {
+		return inv.source.GetContainerStats(ctx, id)
+	}
+
+	statsJSON, err := inv.source.GetStatsJSON(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParseContainerStats(statsJSON, &ctr), nil
+}
+
+// describeStatus rebuilds the human-readable status string that ContainerList
+// returns (e.g. "Up 2 hours (healthy)"), so container_info labels match
+// between inventory and list mode.
+func describeStatus(c *Container, now time.Time) string {
+	switch c.State {
+	case "running":
+		up := units.HumanDuration(now.Sub(c.StartedAt))
+		switch c.Health {
+		case "":
+			return "Up " + up
+		case "starting":
+			return fmt.Sprintf("Up %s (health: starting)", up)
+		default:
+			return fmt.Sprintf("Up %s (%s)", up, c.Health)
+		}
+	case "paused":
+		return fmt.Sprintf("Up %s (Paused)", units.HumanDuration(now.Sub(c.StartedAt)))
+	case "restarting":
+		return fmt.Sprintf("Restarting (%d) %s ago", c.ExitCode, units.HumanDuration(now.Sub(c.FinishedAt)))
+	case "removing":
+		return "Removal In Progress"
+	case "dead":
+		return "Dead"
+	case "created":
+		return "Created"
+	case "exited":
+		if c.FinishedAt.IsZero() {
+			return ""
+		}
+		return fmt.Sprintf("Exited (%d) %s ago", c.ExitCode, units.HumanDuration(now.Sub(c.FinishedAt)))
+	default:
+		return c.State
+	}
+}
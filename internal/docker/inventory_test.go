@@ -0,0 +1,235 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeInventorySource implements inventorySource for testing.
+type fakeInventorySource struct {
+	mu         sync.Mutex
+	containers map[string]Container
+	listCalls  int
+	statsCalls int // GetContainerStats, which inspects
+	msgs       chan events.Message
+	errs       chan error
+	subscribed chan struct{}
+}
+
+func newFakeInventorySource(containers ...Container) *fakeInventorySource {
+	f := &fakeInventorySource{
+		containers: make(map[string]Container),
+		subscribed: make(chan struct{}, 10),
+	}
+	for _, c := range containers {
+		f.containers[c.ID] = c
+	}
+	return f
+}
+
+func (f *fakeInventorySource) ListContainers(_ context.Context) ([]Container, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listCalls++
+	out := make([]Container, 0, len(f.containers))
+	for _, c := range f.containers {
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (f *fakeInventorySource) InspectContainer(_ context.Context, id string) (*Container, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.containers[id]
+	if !ok {
+		return nil, fmt.Errorf("inspecting container %s: %w", id, errdefs.NotFound(fmt.Errorf("no such container")))
+	}
+	return &c, nil
+}
+
+func (f *fakeInventorySource) GetContainerStats(_ context.Context, id string) (*Stats, error) {
+	f.mu.Lock()
+	f.statsCalls++
+	f.mu.Unlock()
+	return &Stats{ContainerID: id}, nil
+}
+
+func (f *fakeInventorySource) GetStatsJSON(_ context.Context, _ string) (*types.StatsJSON, error) {
+	var s types.StatsJSON
+	s.PidsStats.Current = 7
+	return &s, nil
+}
+
+func (f *fakeInventorySource) Events(_ context.Context, _ time.Time, _ ...events.Action) (<-chan events.Message, <-chan error) {
+	f.mu.Lock()
+	f.msgs = make(chan events.Message)
+	f.errs = make(chan error, 1)
+	msgs, errs := f.msgs, f.errs
+	f.mu.Unlock()
+	f.subscribed <- struct{}{}
+	return msgs, errs
+}
+
+func (f *fakeInventorySource) set(c Container) {
+	f.mu.Lock()
+	f.containers[c.ID] = c
+	f.mu.Unlock()
+}
+
+func (f *fakeInventorySource) delete(id string) {
+	f.mu.Lock()
+	delete(f.containers, id)
+	f.mu.Unlock()
+}
+
+func (f *fakeInventorySource) send(action events.Action, id string) {
+	f.mu.Lock()
+	msgs := f.msgs
+	f.mu.Unlock()
+	msgs <- events.Message{Type: events.ContainerEventType, Action: action, Actor: events.Actor{ID: id}}
+}
+
+func (f *fakeInventorySource) fail(err error) {
+	f.mu.Lock()
+	errs := f.errs
+	f.mu.Unlock()
+	errs <- err
+}
+
+func listNames(t *testing.T, inv *Inventory) []string {
+	t.Helper()
+	containers, err := inv.ListContainers(context.Background())
+	require.NoError(t, err)
+	names := make([]string, 0, len(containers))
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestInventory_FallsThroughBeforeSync(t *testing.T) {
+	src := newFakeInventorySource(Container{ID: "a", Name: "web", State: "running"})
+	inv := newInventory(src)
+
+	assert.Equal(t, []string{"web"}, listNames(t, inv))
+	assert.Equal(t, 1, src.listCalls)
+}
+
+func TestInventory_StatsUseCachedInspect(t *testing.T) {
+	src := newFakeInventorySource(Container{ID: "a", Name: "web", Image: "nginx:1.27", State: "running", RestartCount: 2})
+	inv := newInventory(src)
+	ctx := context.Background()
+
+	// Before the first sync the inventory knows nothing and falls through
+	_, err := inv.GetContainerStats(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, src.statsCalls)
+
+	require.NoError(t, inv.resync(ctx))
+	stats, err := inv.GetContainerStats(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, src.statsCalls, "synced containers need no inspect")
+	assert.Equal(t, "web", stats.Name)
+	assert.Equal(t, "nginx:1.27", stats.Image)
+	assert.Equal(t, "running", stats.Status)
+	assert.Equal(t, 2, stats.RestartCount)
+	assert.Equal(t, uint64(7), stats.PIDsCurrent)
+}
+
+func TestInventory_FollowsEvents(t *testing.T) {
+	src := newFakeInventorySource(Container{ID: "a", Name: "web", State: "running"})
+	inv := newInventory(src)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go inv.Run(ctx)
+	<-src.subscribed
+
+	// Sending blocks until Run is following, so the initial sync is done
+	src.set(Container{ID: "b", Name: "db", State: "running"})
+	src.send(events.ActionStart, "b")
+
+	src.set(Container{ID: "a", Name: "frontend", State: "running"})
+	src.send(events.ActionRename, "a")
+
+	// A round trip through the unbuffered channel orders the assertions
+	// after the previous event has been applied.
+	src.send(events.ActionUpdate, "b")
+	assert.Equal(t, []string{"db", "frontend"}, listNames(t, inv))
+
+	src.delete("b")
+	src.send(events.ActionDestroy, "b")
+	src.send(events.ActionUpdate, "a")
+	assert.Equal(t, []string{"frontend"}, listNames(t, inv))
+
+	// Events for containers that are already gone drop them too
+	src.set(Container{ID: "c", Name: "tmp", State: "running"})
+	src.send(events.ActionCreate, "c")
+	src.delete("c")
+	src.send(events.ActionDie, "c")
+	src.send(events.ActionUpdate, "a")
+	assert.Equal(t, []string{"frontend"}, listNames(t, inv))
+
+	src.mu.Lock()
+	assert.Equal(t, 1, src.listCalls, "scrapes must not hit ContainerList once synced")
+	src.mu.Unlock()
+}
+
+func TestInventory_ResyncsAfterReconnect(t *testing.T) {
+	src := newFakeInventorySource(Container{ID: "a", Name: "web", State: "running"})
+	inv := newInventory(src)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go inv.Run(ctx)
+	<-src.subscribed
+
+	// Changes made while the stream is down are only visible via resync
+	src.send(events.ActionUpdate, "a")
+	src.set(Container{ID: "b", Name: "db", State: "running"})
+	src.fail(fmt.Errorf("unexpected EOF"))
+
+	select {
+	case <-src.subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("inventory did not resubscribe")
+	}
+	src.send(events.ActionUpdate, "a")
+
+	assert.Equal(t, []string{"db", "web"}, listNames(t, inv))
+}
+
+func TestDescribeStatus(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		c    Container
+		want string
+	}{
+		{"running", Container{State: "running", StartedAt: now.Add(-2 * time.Hour)}, "Up 2 hours"},
+		{"healthy", Container{State: "running", Health: "healthy", StartedAt: now.Add(-5 * time.Minute)}, "Up 5 minutes (healthy)"},
+		{"starting", Container{State: "running", Health: "starting", StartedAt: now.Add(-10 * time.Second)}, "Up 10 seconds (health: starting)"},
+		{"paused", Container{State: "paused", StartedAt: now.Add(-3 * time.Hour)}, "Up 3 hours (Paused)"},
+		{"exited", Container{State: "exited", ExitCode: 137, FinishedAt: now.Add(-2 * time.Minute)}, "Exited (137) 2 minutes ago"},
+		{"created", Container{State: "created"}, "Created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, describeStatus(&tt.c, now))
+		})
+	}
+}
@@ -11,6 +11,10 @@ const (
 	LabelComposeProject = "com.docker.compose.project"
 )
 
+// LabelStackNamespace is set by `docker stack deploy` on services and their
+// containers. It plays the role of the compose project for swarm stacks.
+const LabelStackNamespace = "com.docker.stack.namespace"
+
 // ContainerLabels holds the standard label set emitted with every metric.
 type ContainerLabels struct {
 	ContainerName  string
@@ -49,6 +53,31 @@ func (l ContainerLabels) Values() []string {
 	return []string{l.ContainerName, l.ComposeService, l.ComposeProject, l.Image}
 }
 
+// ServiceLabels holds the standard label set emitted with every swarm service
+// metric.
+type ServiceLabels struct {
+	ServiceName    string
+	StackNamespace string
+}
+
+// ServiceLabelNames returns the service label keys in a fixed order.
+func ServiceLabelNames() []string {
+	return []string{"service_name", "stack_namespace"}
+}
+
+// ExtractServiceLabels builds the standard label set from a Service.
+func ExtractServiceLabels(s *Service) ServiceLabels {
+	return ServiceLabels{
+		ServiceName:    SanitizeLabelValue(s.Name),
+		StackNamespace: SanitizeLabelValue(s.Labels[LabelStackNamespace]),
+	}
+}
+
+// Values returns label values in the same order as ServiceLabelNames.
+func (l ServiceLabels) Values() []string {
+	return []string{l.ServiceName, l.StackNamespace}
+}
+
 var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_:/.@=-]`)
 
 // SanitizeLabelValue replaces characters that aren't safe in Prometheus label values.
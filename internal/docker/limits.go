@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"strconv"
+	"strings"
+
+	containertypes "github.com/docker/docker/api/types/container"
+)
+
+// Limits holds the resource limits and reservations a container was created
+// with, from its HostConfig. Zero means "not set" for every field except
+// MemorySwap, where -1 means unlimited swap.
+type Limits struct {
+	CPUQuota          int64  // CFS quota in microseconds per period
+	CPUPeriod         int64  // CFS period in microseconds
+	NanoCPUs          int64  // --cpus, in billionths of a CPU
+	CPUShares         int64  // relative weight
+	CpusetCpus        string // e.g. "0-3,6"
+	Memory            int64  // hard limit in bytes
+	MemoryReservation int64  // soft limit in bytes
+	MemorySwap        int64  // memory + swap in bytes
+	PidsLimit         int64
+	RestartPolicy     string
+	RestartMaxRetries int
+}
+
+// limitsFromHostConfig extracts the resource limits of a container.
+func limitsFromHostConfig(hc *containertypes.HostConfig) Limits {
+	l := Limits{
+		CPUQuota:          hc.CPUQuota,
+		CPUPeriod:         hc.CPUPeriod,
+		NanoCPUs:          hc.NanoCPUs,
+		CPUShares:         hc.CPUShares,
+		CpusetCpus:        hc.CpusetCpus,
+		Memory:            hc.Memory,
+		MemoryReservation: hc.MemoryReservation,
+		MemorySwap:        hc.MemorySwap,
+		RestartPolicy:     string(hc.RestartPolicy.Name),
+		RestartMaxRetries: hc.RestartPolicy.MaximumRetryCount,
+	}
+	// 0 and -1 both mean unlimited; keep a single spelling
+	if hc.PidsLimit != nil && *hc.PidsLimit > 0 {
+		l.PidsLimit = *hc.PidsLimit
+	}
+	if l.RestartPolicy == "" {
+		l.RestartPolicy = "no"
+	}
+	return l
+}
+
+// defaultCPUPeriod is the CFS period the kernel uses when none is set, in
+// microseconds.
+const defaultCPUPeriod = 100000
+
+// CPULimitCores returns the CPU limit in cores, from --cpus or from an
+// explicit CFS quota, or 0 if the container may use every CPU.
+func (l Limits) CPULimitCores() float64 {
+	if l.NanoCPUs > 0 {
+		return float64(l.NanoCPUs) / 1e9
+	}
+	if l.CPUQuota > 0 {
+		period := l.CPUPeriod
+		if period <= 0 {
+			period = defaultCPUPeriod
+		}
+		return float64(l.CPUQuota) / float64(period)
+	}
+	return 0
+}
+
+// PinnedCPU returns the CPU the container is pinned to when its cpuset names
+// exactly one.
+func (l Limits) PinnedCPU() (int, bool) {
+	if CountCPUs(l.CpusetCpus) != 1 {
+		return 0, false
+	}
+	lo, _, _ := strings.Cut(strings.TrimSpace(l.CpusetCpus), "-")
+	cpu, err := strconv.Atoi(lo)
+	if err != nil {
+		return 0, false
+	}
+	return cpu, true
+}
+
+// CountCPUs counts the CPUs in a list like "0-3,6,8-9".
+func CountCPUs(list string) uint32 {
+	var n uint32
+	for _, part := range strings.Split(strings.TrimSpace(list), ",") {
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		if !isRange {
+			n++
+			continue
+		}
+		start, err1 := strconv.Atoi(lo)
+		end, err2 := strconv.Atoi(hi)
+		if err1 == nil && err2 == nil && end >= start {
+			n += uint32(end - start + 1)
+		}
+	}
+	return n
+}
@@ -0,0 +1,63 @@
+package docker
+
+import (
+	"testing"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitsFromHostConfig(t *testing.T) {
+	pids := int64(-1)
+	hc := &containertypes.HostConfig{
+		RestartPolicy: containertypes.RestartPolicy{Name: "on-failure", MaximumRetryCount: 5},
+		Resources: containertypes.Resources{
+			CPUShares:         512,
+			Memory:            512 << 20,
+			MemoryReservation: 256 << 20,
+			MemorySwap:        -1,
+			CpusetCpus:        "0-1",
+			PidsLimit:         &pids,
+		},
+	}
+
+	l := limitsFromHostConfig(hc)
+	assert.Equal(t, int64(512), l.CPUShares)
+	assert.Equal(t, int64(512<<20), l.Memory)
+	assert.Equal(t, int64(256<<20), l.MemoryReservation)
+	assert.Equal(t, int64(-1), l.MemorySwap)
+	assert.Equal(t, "0-1", l.CpusetCpus)
+	assert.Equal(t, int64(0), l.PidsLimit, "-1 and 0 both mean unlimited")
+	assert.Equal(t, "on-failure", l.RestartPolicy)
+	assert.Equal(t, 5, l.RestartMaxRetries)
+
+	assert.Equal(t, "no", limitsFromHostConfig(&containertypes.HostConfig{}).RestartPolicy)
+}
+
+func TestLimits_CPULimitCores(t *testing.T) {
+	assert.Equal(t, 1.5, Limits{NanoCPUs: 1_500_000_000}.CPULimitCores())
+	assert.Equal(t, 0.5, Limits{CPUQuota: 50000, CPUPeriod: 100000}.CPULimitCores())
+	assert.Equal(t, 2.0, Limits{CPUQuota: 200000}.CPULimitCores(), "default period")
+	assert.Equal(t, 0.0, Limits{CPUShares: 1024}.CPULimitCores())
+}
+
+func TestLimits_PinnedCPU(t *testing.T) {
+	cpu, ok := Limits{CpusetCpus: "3"}.PinnedCPU()
+	assert.True(t, ok)
+	assert.Equal(t, 3, cpu)
+
+	cpu, ok = Limits{CpusetCpus: "5-5"}.PinnedCPU()
+	assert.True(t, ok)
+	assert.Equal(t, 5, cpu)
+
+	_, ok = Limits{CpusetCpus: "0-1"}.PinnedCPU()
+	assert.False(t, ok)
+	_, ok = Limits{}.PinnedCPU()
+	assert.False(t, ok)
+}
+
+func TestCountCPUs(t *testing.T) {
+	assert.Equal(t, uint32(4), CountCPUs("0-3\n"))
+	assert.Equal(t, uint32(7), CountCPUs("0-3,6,8-9"))
+	assert.Equal(t, uint32(0), CountCPUs(""))
+}
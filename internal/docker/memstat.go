@@ -0,0 +1,39 @@
+package docker
+
+import "strings"
+
+// memoryStatV1Names maps cgroup v1 memory.stat keys to their cgroup v2
+// equivalents. Keys without an equivalent (pgpgin, hierarchical_*, ...) keep
+// their v1 name.
+var memoryStatV1Names = map[string]string{
+	"cache":       "file",
+	"rss":         "anon",
+	"rss_huge":    "anon_thp",
+	"mapped_file": "file_mapped",
+	"dirty":       "file_dirty",
+	"writeback":   "file_writeback",
+}
+
+// MemoryStatName returns the name a memory.stat key is exported under: the
+// cgroup v2 name where a v1 key has one, else the key unchanged.
+func MemoryStatName(key string) string {
+	if name, ok := memoryStatV1Names[key]; ok {
+		return name
+	}
+	return key
+}
+
+// NormalizeMemoryStat renames the keys of a raw memory.stat map to their
+// cgroup v2 names. The v1 "total_*" keys, which repeat every value summed
+// over child cgroups, are dropped: containers have no child cgroups, so they
+// hold the same values as the plain keys.
+func NormalizeMemoryStat(raw map[string]uint64) map[string]uint64 {
+	out := make(map[string]uint64, len(raw))
+	for k, v := range raw {
+		if strings.HasPrefix(k, "total_") {
+			continue
+		}
+		out[MemoryStatName(k)] = v
+	}
+	return out
+}
@@ -0,0 +1,29 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeMemoryStat(t *testing.T) {
+	v1 := map[string]uint64{
+		"cache":       100,
+		"rss":         200,
+		"mapped_file": 10,
+		"shmem":       5,
+		"pgmajfault":  3,
+		"total_cache": 100,
+		"total_rss":   200,
+	}
+	assert.Equal(t, map[string]uint64{
+		"file":        100,
+		"anon":        200,
+		"file_mapped": 10,
+		"shmem":       5,
+		"pgmajfault":  3,
+	}, NormalizeMemoryStat(v1))
+
+	v2 := map[string]uint64{"file": 100, "anon": 200, "kernel_stack": 16384, "sock": 4096}
+	assert.Equal(t, v2, NormalizeMemoryStat(v2))
+}
@@ -0,0 +1,174 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/netip"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+)
+
+// Network holds a Docker network with its attachments and address usage.
+type Network struct {
+	ID         string
+	Name       string
+	Driver     string
+	Scope      string // local, global or swarm
+	Internal   bool
+	Attachable bool
+	Containers int // running containers attached through this daemon
+	Subnets    []Subnet
+}
+
+// Subnet is one IPAM pool of a network.
+type Subnet struct {
+	Subnet string // CIDR as configured
+
+	// Capacity is the number of addresses IPAM can hand out: the IP range if
+	// one is set, otherwise the whole subnet, less the reserved network and
+	// (IPv4) broadcast addresses.
+	Capacity float64
+
+	// Allocated counts the addresses in use within the pool: attached
+	// endpoints, the gateway and auxiliary addresses.
+	Allocated float64
+}
+
+// ListNetworks returns every network with the number of running containers
+// attached and, for each configured subnet, its capacity and allocated
+// addresses. The network list doesn't carry attachments (API 1.28+), so
+// endpoints are taken from the running container list rather than inspecting
+// each network. Config-only networks hold no endpoints and are left out.
+func (c *Client) ListNetworks(ctx context.Context) ([]Network, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	summaries, err := c.cli.NetworkList(ctx, network.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing networks: %w", err)
+	}
+	containers, err := c.cli.ContainerList(ctx, containertypes.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing containers: %w", err)
+	}
+
+	// Endpoints name their network by ID; older daemons leave it empty, so
+	// fall back to the name they are keyed by.
+	byName := make(map[string]string, len(summaries))
+	for _, s := range summaries {
+		byName[s.Name] = s.ID
+	}
+	attached := make(map[string]int, len(summaries))
+	addrs := make(map[string][]netip.Addr, len(summaries))
+	for _, ctr := range containers {
+		if ctr.NetworkSettings == nil {
+			continue
+		}
+		for name, es := range ctr.NetworkSettings.Networks {
+			if es == nil {
+				continue
+			}
+			id := es.NetworkID
+			if id == "" {
+				id = byName[name]
+			}
+			attached[id]++
+			for _, ip := range []string{es.IPAddress, es.GlobalIPv6Address} {
+				if addr, err := netip.ParseAddr(ip); err == nil {
+					addrs[id] = append(addrs[id], addr)
+				}
+			}
+		}
+	}
+
+	networks := make([]Network, 0, len(summaries))
+	for _, s := range summaries {
+		if s.ConfigOnly {
+			continue
+		}
+		n := Network{
+			ID:         s.ID,
+			Name:       s.Name,
+			Driver:     s.Driver,
+			Scope:      s.Scope,
+			Internal:   s.Internal,
+			Attachable: s.Attachable,
+			Containers: attached[s.ID],
+		}
+		for _, cfg := range s.IPAM.Config {
+			if sub, ok := subnetUsage(cfg, addrs[s.ID]); ok {
+				n.Subnets = append(n.Subnets, sub)
+			}
+		}
+		networks = append(networks, n)
+	}
+	return networks, nil
+}
+
+// subnetUsage computes the capacity and allocation of one IPAM pool, the
+// way the default IPAM driver reserves addresses: the subnet's network
+// address, and its broadcast address on IPv4 subnets up to /30, are
+// never handed out. A subnet that doesn't parse is skipped.
+func subnetUsage(cfg network.IPAMConfig, endpoints []netip.Addr) (Subnet, bool) {
+	subnet, err := netip.ParsePrefix(cfg.Subnet)
+	if err != nil {
+		return Subnet{}, false
+	}
+	subnet = subnet.Masked()
+
+	pool := subnet
+	if cfg.IPRange != "" {
+		if r, err := netip.ParsePrefix(cfg.IPRange); err == nil && subnet.Contains(r.Addr()) {
+			pool = r.Masked()
+		}
+	}
+
+	capacity := math.Exp2(float64(pool.Addr().BitLen() - pool.Bits()))
+	reserved := []netip.Addr{subnet.Addr()}
+	if subnet.Addr().Is4() && subnet.Bits() <= 30 {
+		reserved = append(reserved, lastAddr(subnet))
+	}
+	for _, addr := range reserved {
+		if pool.Contains(addr) {
+			capacity--
+		}
+	}
+
+	// The gateway and auxiliary addresses may sit outside the IP range, in
+	// which case they don't use up the pool
+	used := make(map[netip.Addr]struct{}, len(endpoints)+1)
+	add := func(addr netip.Addr) {
+		if pool.Contains(addr) {
+			used[addr] = struct{}{}
+		}
+	}
+	for _, addr := range endpoints {
+		add(addr)
+	}
+	if gw, err := netip.ParseAddr(cfg.Gateway); err == nil {
+		add(gw)
+	}
+	for _, aux := range cfg.AuxAddress {
+		if addr, err := netip.ParseAddr(aux); err == nil {
+			add(addr)
+		}
+	}
+
+	return Subnet{
+		Subnet:    cfg.Subnet,
+		Capacity:  math.Max(capacity, 0),
+		Allocated: float64(len(used)),
+	}, true
+}
+
+// lastAddr returns the highest address of a prefix.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
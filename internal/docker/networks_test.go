@@ -0,0 +1,81 @@
+package docker
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubnetUsage(t *testing.T) {
+	addrs := func(ips ...string) []netip.Addr {
+		out := make([]netip.Addr, len(ips))
+		for i, ip := range ips {
+			out[i] = netip.MustParseAddr(ip)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name      string
+		cfg       network.IPAMConfig
+		endpoints []netip.Addr
+		capacity  float64
+		allocated float64
+	}{
+		{
+			name:      "bridge /16 with gateway",
+			cfg:       network.IPAMConfig{Subnet: "172.17.0.0/16", Gateway: "172.17.0.1"},
+			endpoints: addrs("172.17.0.2", "172.17.0.3"),
+			capacity:  65534,
+			allocated: 3,
+		},
+		{
+			name:      "small /28 with auxiliary address",
+			cfg:       network.IPAMConfig{Subnet: "10.1.0.0/28", Gateway: "10.1.0.1", AuxAddress: map[string]string{"host": "10.1.0.14"}},
+			endpoints: addrs("10.1.0.2", "192.168.0.5"),
+			capacity:  14,
+			allocated: 3,
+		},
+		{
+			name:      "ip range excludes gateway",
+			cfg:       network.IPAMConfig{Subnet: "10.2.0.0/24", IPRange: "10.2.0.128/25", Gateway: "10.2.0.1"},
+			endpoints: addrs("10.2.0.130"),
+			capacity:  127, // broadcast 10.2.0.255 is in the range
+			allocated: 1,
+		},
+		{
+			name:      "ipv6 /64",
+			cfg:       network.IPAMConfig{Subnet: "fd00:1::/64", Gateway: "fd00:1::1"},
+			endpoints: addrs("fd00:1::2"),
+			capacity:  1<<64 - 1,
+			allocated: 2,
+		},
+		{
+			name:     "point-to-point /31",
+			cfg:      network.IPAMConfig{Subnet: "10.3.0.0/31"},
+			capacity: 1,
+		},
+		{
+			name:      "unmasked subnet",
+			cfg:       network.IPAMConfig{Subnet: "10.4.0.7/30"},
+			endpoints: addrs("10.4.0.5", "10.4.0.5"),
+			capacity:  2,
+			allocated: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, ok := subnetUsage(tt.cfg, tt.endpoints)
+			require.True(t, ok)
+			assert.Equal(t, tt.cfg.Subnet, sub.Subnet)
+			assert.Equal(t, tt.capacity, sub.Capacity)
+			assert.Equal(t, tt.allocated, sub.Allocated)
+		})
+	}
+
+	_, ok := subnetUsage(network.IPAMConfig{Subnet: "not-a-subnet"}, nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,127 @@
+package docker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+// ClientPool hands out Docker clients for probe targets, creating one per key
+// on first use and closing it after it has sat idle for the idle timeout.
+// Clients in use by a probe are never evicted.
+type ClientPool struct {
+	timeout time.Duration
+	idle    time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*pooledClient
+}
+
+type pooledClient struct {
+	client   *Client
+	refs     int
+	lastUsed time.Time
+}
+
+// NewClientPool creates a pool. timeout is the per-request timeout of every
+// client it creates; idleTimeout is how long an unused client is kept.
+func NewClientPool(timeout, idleTimeout time.Duration) *ClientPool {
+	return &ClientPool{
+		timeout: timeout,
+		idle:    idleTimeout,
+		clients: make(map[string]*pooledClient),
+	}
+}
+
+// Get returns the client for key, creating it from cfg if the pool doesn't
+// hold one. The caller must call release when done with the client.
+func (p *ClientPool) Get(key string, cfg config.DockerConfig) (client *Client, release func(), err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pc, ok := p.clients[key]
+	if !ok {
+		c, err := NewClient(cfg, p.timeout)
+		if err != nil {
+			return nil, nil, err
+		}
+		pc = &pooledClient{client: c}
+		p.clients[key] = pc
+	}
+	pc.refs++
+
+	release = func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		pc.refs--
+		pc.lastUsed = time.Now()
+	}
+	return pc.client, release, nil
+}
+
+// Len reports how many clients the pool holds.
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// Run evicts idle clients periodically until ctx is canceled, then closes
+// every client left in the pool.
+func (p *ClientPool) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.sweepInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.closeAll()
+			return
+		case now := <-ticker.C:
+			p.evictIdle(now)
+		}
+	}
+}
+
+// sweepInterval checks often enough that a client outlives its idle timeout
+// by at most half of it, capped at a minute.
+func (p *ClientPool) sweepInterval() time.Duration {
+	interval := p.idle / 2
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	if interval < time.Second {
+		interval = time.Second
+	}
+	return interval
+}
+
+// evictIdle closes and drops clients unused since before now minus the idle
+// timeout.
+func (p *ClientPool) evictIdle(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, pc := range p.clients {
+		if pc.refs > 0 || now.Sub(pc.lastUsed) < p.idle {
+			continue
+		}
+		pc.client.Close()
+		delete(p.clients, key)
+		log.WithField("key", key).Debug("Evicted idle probe client")
+	}
+}
+
+func (p *ClientPool) closeAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, pc := range p.clients {
+		pc.client.Close()
+		delete(p.clients, key)
+	}
+}
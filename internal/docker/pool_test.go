@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+func TestClientPool_ReusesClientPerKey(t *testing.T) {
+	pool := NewClientPool(time.Second, time.Minute)
+	cfg := config.DockerConfig{Host: "tcp://10.0.0.5:2376"}
+
+	a, releaseA, err := pool.Get("prod|tcp://10.0.0.5:2376", cfg)
+	require.NoError(t, err)
+	releaseA()
+
+	b, releaseB, err := pool.Get("prod|tcp://10.0.0.5:2376", cfg)
+	require.NoError(t, err)
+	releaseB()
+
+	assert.Same(t, a, b)
+	assert.Equal(t, 1, pool.Len())
+
+	_, releaseC, err := pool.Get("prod|tcp://10.0.0.6:2376", config.DockerConfig{Host: "tcp://10.0.0.6:2376"})
+	require.NoError(t, err)
+	releaseC()
+	assert.Equal(t, 2, pool.Len())
+}
+
+func TestClientPool_InvalidHost(t *testing.T) {
+	pool := NewClientPool(time.Second, time.Minute)
+
+	_, _, err := pool.Get("x", config.DockerConfig{Host: "not a host"})
+	assert.Error(t, err)
+	assert.Equal(t, 0, pool.Len())
+}
+
+func TestClientPool_EvictsIdleClients(t *testing.T) {
+	pool := NewClientPool(time.Second, time.Minute)
+
+	_, releaseIdle, err := pool.Get("idle", config.DockerConfig{Host: "tcp://10.0.0.5:2376"})
+	require.NoError(t, err)
+	releaseIdle()
+
+	// Held by an in-flight probe: kept no matter how old
+	_, releaseBusy, err := pool.Get("busy", config.DockerConfig{Host: "tcp://10.0.0.6:2376"})
+	require.NoError(t, err)
+	defer releaseBusy()
+
+	pool.evictIdle(time.Now().Add(30 * time.Second))
+	assert.Equal(t, 2, pool.Len(), "nothing idle for a minute yet")
+
+	pool.evictIdle(time.Now().Add(2 * time.Minute))
+	assert.Equal(t, 1, pool.Len())
+
+	_, release, err := pool.Get("busy", config.DockerConfig{Host: "tcp://10.0.0.6:2376"})
+	require.NoError(t, err)
+	release()
+	assert.Equal(t, 1, pool.Len())
+}
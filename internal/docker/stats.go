@@ -19,6 +19,7 @@ type Stats struct {
 	MemorySwap       uint64
 	MemoryWorkingSet uint64
 	MemoryFailcnt    uint64
+	MemoryStat       map[string]uint64 // raw memory.stat, v1 or v2 key names
 
 	// CPU (raw nanosecond counters)
 	CPUUsageTotal       uint64
@@ -27,6 +28,13 @@ type Stats struct {
 	CPUThrottledPeriods uint64
 	CPUThrottledTime    uint64
 	OnlineCPUs          uint32
+	CPUUsagePerCPU      []uint64 // indexed by CPU number; cgroup v1 only
+
+	// CPU usage between the previous and current sample, as docker stats
+	// shows it divided by 100: 1.0 is one CPU fully busy. Only set when the
+	// response carries a previous sample.
+	CPUUsageRatio    float64
+	HasCPUUsageRatio bool
 
 	// Network per interface
 	Networks map[string]NetworkStats
@@ -69,6 +77,23 @@ type BlockIOStats struct {
 	WriteBytes uint64
 	ReadOps    uint64
 	WriteOps   uint64
+
+	// Discards, reported by cgroup v2 io.stat and by newer v1 kernels
+	DiscardBytes uint64
+	DiscardOps   uint64
+	HasDiscard   bool
+
+	// I/O scheduler counters, only reported on cgroup v1 with the CFQ or BFQ
+	// scheduler
+	ReadServiceTime  uint64 // nanoseconds
+	WriteServiceTime uint64 // nanoseconds
+	ReadWaitTime     uint64 // nanoseconds
+	WriteWaitTime    uint64 // nanoseconds
+	ReadMerged       uint64
+	WriteMerged      uint64
+	Queued           uint64 // requests currently queued
+	IOTime           uint64 // milliseconds of disk time
+	HasSchedStats    bool
 }
 
 // Container holds basic container info from a list call.
@@ -81,8 +106,29 @@ type Container struct {
 	State        string
 	Health       string
 	StartedAt    time.Time
+	FinishedAt   time.Time
 	RestartCount int
 	ExitCode     int
+	OOMKilled    bool // last exit was an OOM kill; cleared on start
+	CgroupParent string
+	NetworkMode  string // "bridge", "host", "container:<id>", ...
+	Pid          int    // init process on the daemon's host; 0 when not running
+	Endpoints    []Endpoint
+	Limits       Limits
+
+	// Filesystem sizes, only set by ListContainerSizes
+	SizeRw     int64
+	SizeRootFs int64
+}
+
+// Endpoint is a container's attachment to one Docker network, from inspect.
+type Endpoint struct {
+	Network     string // network name
+	NetworkID   string
+	Driver      string // "" when the network list couldn't be fetched
+	MacAddress  string
+	IPAddress   string // IPv4, or the global IPv6 address on IPv6-only networks
+	IPPrefixLen int
 }
 
 // SystemInfo holds Docker daemon info.
@@ -94,13 +140,13 @@ type SystemInfo struct {
 	Volumes           int
 	Networks          int
 	ServerVersion     string
+	MemTotal          int64
+	NCPU              int
 }
 
 // ParseDockerStats converts raw Docker API responses into our Stats struct.
 func ParseDockerStats(statsJSON *types.StatsJSON, containerJSON *types.ContainerJSON) *Stats {
-	s := &Stats{
-		Timestamp: statsJSON.Read,
-	}
+	s := ParseResourceStats(statsJSON)
 
 	// Container identity
 	s.ContainerID = containerJSON.ID
@@ -121,11 +167,40 @@ func ParseDockerStats(statsJSON *types.StatsJSON, containerJSON *types.Container
 		}
 	}
 
+	return s
+}
+
+// ParseContainerStats converts a stats response, taking container identity
+// from an already listed or inspected container instead of a fresh inspect.
+func ParseContainerStats(statsJSON *types.StatsJSON, ctr *Container) *Stats {
+	s := ParseResourceStats(statsJSON)
+	s.ContainerID = ctr.ID
+	s.Name = ctr.Name
+	s.Image = ctr.Image
+	s.Labels = ctr.Labels
+	s.Status = ctr.State
+	s.Health = ctr.Health
+	s.StartedAt = ctr.StartedAt
+	s.RestartCount = ctr.RestartCount
+	s.ExitCode = ctr.ExitCode
+	return s
+}
+
+// ParseResourceStats converts the resource part of a stats response (memory,
+// CPU, network, PIDs, block I/O) and leaves container identity empty. Sources
+// that build a StatsJSON themselves, such as the cgroup reader, use this
+// directly.
+func ParseResourceStats(statsJSON *types.StatsJSON) *Stats {
+	s := &Stats{
+		Timestamp: statsJSON.Read,
+	}
+
 	// Memory
 	parseMemoryStats(s, &statsJSON.MemoryStats)
 
 	// CPU
 	parseCPUStats(s, &statsJSON.CPUStats)
+	s.CPUUsageRatio, s.HasCPUUsageRatio = cpuUsageRatio(statsJSON)
 
 	// Network
 	s.Networks = make(map[string]NetworkStats, len(statsJSON.Networks))
@@ -155,6 +230,7 @@ func parseMemoryStats(s *Stats, mem *containertypes.MemoryStats) {
 	s.MemoryUsage = mem.Usage
 	s.MemoryLimit = mem.Limit
 	s.MemoryFailcnt = mem.Failcnt
+	s.MemoryStat = mem.Stats
 
 	log.WithFields(log.Fields{
 		"usage": mem.Usage,
@@ -201,6 +277,32 @@ func parseCPUStats(s *Stats, cpu *containertypes.CPUStats) {
 	s.CPUThrottledPeriods = cpu.ThrottlingData.ThrottledPeriods
 	s.CPUThrottledTime = cpu.ThrottlingData.ThrottledTime
 	s.OnlineCPUs = cpu.OnlineCPUs
+	s.CPUUsagePerCPU = cpu.CPUUsage.PercpuUsage
+}
+
+// cpuUsageRatio computes CPU usage the way the docker CLI does: the
+// container's CPU time over host CPU time between the two samples, scaled by
+// the online CPUs. Sources without host CPU time (the cgroup reader) divide by
+// the wall time between the samples instead, which is the same figure.
+func cpuUsageRatio(s *types.StatsJSON) (float64, bool) {
+	cur, pre := &s.CPUStats, &s.PreCPUStats
+	if cur.CPUUsage.TotalUsage < pre.CPUUsage.TotalUsage {
+		return 0, false // counter reset: the container restarted
+	}
+	cpuDelta := float64(cur.CPUUsage.TotalUsage - pre.CPUUsage.TotalUsage)
+
+	// The daemon sends a zeroed precpu_stats with the first streamed sample
+	if pre.SystemUsage > 0 && cur.SystemUsage > pre.SystemUsage {
+		cpus := cur.OnlineCPUs
+		if cpus == 0 {
+			cpus = uint32(len(cur.CPUUsage.PercpuUsage))
+		}
+		return cpuDelta / float64(cur.SystemUsage-pre.SystemUsage) * float64(cpus), true
+	}
+	if cur.SystemUsage == 0 && !s.PreRead.IsZero() && s.Read.After(s.PreRead) {
+		return cpuDelta / float64(s.Read.Sub(s.PreRead).Nanoseconds()), true
+	}
+	return 0, false
 }
 
 func parseBlockIOStats(bio *containertypes.BlkioStats) map[string]BlockIOStats {
@@ -214,6 +316,9 @@ func parseBlockIOStats(bio *containertypes.BlkioStats) map[string]BlockIOStats {
 			d.ReadBytes = entry.Value
 		case "Write", "write":
 			d.WriteBytes = entry.Value
+		case "Discard", "discard":
+			d.DiscardBytes = entry.Value
+			d.HasDiscard = true
 		}
 		devices[key] = d
 	}
@@ -226,13 +331,68 @@ func parseBlockIOStats(bio *containertypes.BlkioStats) map[string]BlockIOStats {
 			d.ReadOps = entry.Value
 		case "Write", "write":
 			d.WriteOps = entry.Value
+		case "Discard", "discard":
+			d.DiscardOps = entry.Value
+			d.HasDiscard = true
 		}
 		devices[key] = d
 	}
 
+	parseSchedStats(devices, bio.IoServiceTimeRecursive, func(d *BlockIOStats, read bool, v uint64) {
+		if read {
+			d.ReadServiceTime = v
+		} else {
+			d.WriteServiceTime = v
+		}
+	})
+	parseSchedStats(devices, bio.IoWaitTimeRecursive, func(d *BlockIOStats, read bool, v uint64) {
+		if read {
+			d.ReadWaitTime = v
+		} else {
+			d.WriteWaitTime = v
+		}
+	})
+	parseSchedStats(devices, bio.IoMergedRecursive, func(d *BlockIOStats, read bool, v uint64) {
+		if read {
+			d.ReadMerged = v
+		} else {
+			d.WriteMerged = v
+		}
+	})
+	parseSchedStats(devices, bio.IoQueuedRecursive, func(d *BlockIOStats, _ bool, v uint64) {
+		d.Queued += v
+	})
+	for _, entry := range bio.IoTimeRecursive {
+		key := deviceKey(entry.Major, entry.Minor)
+		d := devices[key]
+		d.IOTime = entry.Value
+		d.HasSchedStats = true
+		devices[key] = d
+	}
+
 	return devices
 }
 
+// parseSchedStats applies the Read and Write entries of one CFQ/BFQ blkio
+// file. The Sync, Async, Discard and Total breakdowns are ignored.
+func parseSchedStats(devices map[string]BlockIOStats, entries []containertypes.BlkioStatEntry, set func(d *BlockIOStats, read bool, v uint64)) {
+	for _, entry := range entries {
+		var read bool
+		switch entry.Op {
+		case "Read", "read":
+			read = true
+		case "Write", "write":
+		default:
+			continue
+		}
+		key := deviceKey(entry.Major, entry.Minor)
+		d := devices[key]
+		set(&d, read, entry.Value)
+		d.HasSchedStats = true
+		devices[key] = d
+	}
+}
+
 func deviceKey(major, minor uint64) string {
 	return fmt.Sprintf("%d:%d", major, minor)
 }
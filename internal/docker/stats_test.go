@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	containertypes "github.com/docker/docker/api/types/container"
@@ -79,6 +80,10 @@ func TestParseDockerStats(t *testing.T) {
 	assert.Equal(t, uint64(10), stats.CPUThrottledPeriods)
 	assert.Equal(t, uint64(5000000000), stats.CPUThrottledTime)
 	assert.Equal(t, uint32(2), stats.OnlineCPUs)
+	assert.Equal(t, []uint64{250000000000, 250000000000}, stats.CPUUsagePerCPU)
+	// 10s of CPU over 100s of host CPU time on 2 CPUs: docker stats shows 20%
+	assert.True(t, stats.HasCPUUsageRatio)
+	assert.InDelta(t, 0.2, stats.CPUUsageRatio, 1e-9)
 
 	// Network
 	require.Contains(t, stats.Networks, "eth0")
@@ -102,6 +107,18 @@ func TestParseDockerStats(t *testing.T) {
 	assert.Equal(t, uint64(2097152), bio.WriteBytes)
 	assert.Equal(t, uint64(100), bio.ReadOps)
 	assert.Equal(t, uint64(200), bio.WriteOps)
+	assert.False(t, bio.HasDiscard)
+
+	// Scheduler counters (cgroup v1, CFQ/BFQ)
+	assert.True(t, bio.HasSchedStats)
+	assert.Equal(t, uint64(1500000000), bio.ReadServiceTime)
+	assert.Equal(t, uint64(3000000000), bio.WriteServiceTime)
+	assert.Equal(t, uint64(500000000), bio.ReadWaitTime)
+	assert.Equal(t, uint64(2000000000), bio.WriteWaitTime)
+	assert.Equal(t, uint64(7), bio.ReadMerged)
+	assert.Equal(t, uint64(11), bio.WriteMerged)
+	assert.Equal(t, uint64(3), bio.Queued)
+	assert.Equal(t, uint64(4200), bio.IOTime)
 }
 
 func TestParseDockerStats_NoHealth(t *testing.T) {
@@ -134,6 +151,72 @@ func TestParseDockerStats_CgroupV2Memory(t *testing.T) {
 	assert.Equal(t, uint64(65536000), stats.MemoryRSS)
 }
 
+func TestCPUUsageRatio(t *testing.T) {
+	read := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	sample := func(total, system uint64, online uint32) containertypes.CPUStats {
+		return containertypes.CPUStats{
+			CPUUsage:    containertypes.CPUUsage{TotalUsage: total, PercpuUsage: []uint64{total / 2, total / 2}},
+			SystemUsage: system,
+			OnlineCPUs:  online,
+		}
+	}
+
+	tests := []struct {
+		name  string
+		stats types.StatsJSON
+		want  float64
+		ok    bool
+	}{
+		{
+			name: "host cpu time",
+			stats: types.StatsJSON{Stats: types.Stats{
+				CPUStats:    sample(3e9, 104e9, 4),
+				PreCPUStats: sample(1e9, 100e9, 4),
+			}},
+			want: 2, ok: true,
+		},
+		{
+			name: "online cpus from percpu length",
+			stats: types.StatsJSON{Stats: types.Stats{
+				CPUStats:    sample(2e9, 104e9, 0),
+				PreCPUStats: sample(1e9, 100e9, 0),
+			}},
+			want: 0.5, ok: true,
+		},
+		{
+			name: "first streamed sample",
+			stats: types.StatsJSON{Stats: types.Stats{
+				CPUStats: sample(3e9, 104e9, 4),
+			}},
+		},
+		{
+			name: "wall time without host cpu time",
+			stats: types.StatsJSON{Stats: types.Stats{
+				Read:        read,
+				PreRead:     read.Add(-2 * time.Second),
+				CPUStats:    sample(3e9, 0, 4),
+				PreCPUStats: sample(2e9, 0, 4),
+			}},
+			want: 0.5, ok: true,
+		},
+		{
+			name: "restarted",
+			stats: types.StatsJSON{Stats: types.Stats{
+				CPUStats:    sample(1e9, 104e9, 4),
+				PreCPUStats: sample(3e9, 100e9, 4),
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := cpuUsageRatio(&tt.stats)
+			assert.Equal(t, tt.ok, ok)
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
+
 func TestTrimLeadingSlash(t *testing.T) {
 	assert.Equal(t, "container", trimLeadingSlash("/container"))
 	assert.Equal(t, "container", trimLeadingSlash("container"))
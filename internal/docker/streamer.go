@@ -0,0 +1,249 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	streamMinBackoff = 1 * time.Second
+	streamMaxBackoff = 30 * time.Second
+
+	// streamMinMaxAge is the shortest age after which a sample counts as
+	// stale. The daemon sends one every second, so a live stream stays well
+	// under it.
+	streamMinMaxAge = 15 * time.Second
+)
+
+// StatsStream is an open stream=true stats request for one container.
+type StatsStream struct {
+	inspect *types.ContainerJSON
+	body    io.ReadCloser
+	dec     *json.Decoder
+}
+
+func newStatsStream(body io.ReadCloser, inspect *types.ContainerJSON) *StatsStream {
+	return &StatsStream{
+		inspect: inspect,
+		body:    body,
+		dec:     json.NewDecoder(body),
+	}
+}
+
+// Next blocks until the daemon sends the next sample. It returns io.EOF when
+// the container stops.
+func (s *StatsStream) Next() (*types.StatsJSON, error) {
+	var statsJSON types.StatsJSON
+	if err := s.dec.Decode(&statsJSON); err != nil {
+		return nil, err
+	}
+	return &statsJSON, nil
+}
+
+// Close releases the underlying HTTP response.
+func (s *StatsStream) Close() error {
+	return s.body.Close()
+}
+
+// statsStreamSource is the subset of Client used by StatsStreamer.
+type statsStreamSource interface {
+	OpenStatsStream(ctx context.Context, id string) (*StatsStream, error)
+}
+
+// streamFrame is the latest raw sample for a container, paired with the
+// inspect data captured when its stream was last opened.
+type streamFrame struct {
+	stats    *types.StatsJSON
+	inspect  *types.ContainerJSON
+	received time.Time
+}
+
+// containerStream is one long-lived stats reader. ready is closed after the
+// first sample, or after the first attempt fails (firstErr is then set).
+type containerStream struct {
+	cancel    context.CancelFunc
+	ready     chan struct{}
+	readyOnce sync.Once
+	firstErr  error
+	latest    atomic.Pointer[streamFrame]
+}
+
+// StatsStreamer keeps one stream=true stats reader per running container, so
+// a scrape reads the latest sample from memory instead of paying the ~1s a
+// one-shot request spends waiting for precpu_stats.
+//
+// Streams start the first time a container's stats are requested and stop
+// once the container no longer shows up as running in ListContainers. A
+// sample older than maxAge is not served, so a stream that keeps failing
+// reports the container as unavailable instead of freezing its counters.
+type StatsStreamer struct {
+	source statsStreamSource
+	lister ContainerLister
+	maxAge time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	streams map[string]*containerStream
+}
+
+// NewStatsStreamer creates a streamer that opens streams through client and
+// lists containers through lister (the client itself or an Inventory).
+// Samples older than maxAge, or 15s if that is longer, are treated as stale.
+func NewStatsStreamer(client *Client, lister ContainerLister, maxAge time.Duration) *StatsStreamer {
+	return newStatsStreamer(client, lister, max(maxAge, streamMinMaxAge))
+}
+
+func newStatsStreamer(source statsStreamSource, lister ContainerLister, maxAge time.Duration) *StatsStreamer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &StatsStreamer{
+		source:  source,
+		lister:  lister,
+		maxAge:  maxAge,
+		ctx:     ctx,
+		cancel:  cancel,
+		streams: make(map[string]*containerStream),
+	}
+}
+
+// ListContainers lists containers and stops streams for containers that are
+// gone or no longer running.
+func (s *StatsStreamer) ListContainers(ctx context.Context) ([]Container, error) {
+	containers, err := s.lister.ListContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	running := make(map[string]struct{}, len(containers))
+	for _, c := range containers {
+		if c.State == "running" {
+			running[c.ID] = struct{}{}
+		}
+	}
+
+	s.mu.Lock()
+	for id, cs := range s.streams {
+		if _, ok := running[id]; !ok {
+			cs.cancel()
+			delete(s.streams, id)
+		}
+	}
+	s.mu.Unlock()
+
+	return containers, nil
+}
+
+// GetContainerStats returns the latest streamed sample for the container,
+// starting a stream first if there isn't one yet. It fails if that sample is
+// older than the streamer's maxAge.
+func (s *StatsStreamer) GetContainerStats(ctx context.Context, id string) (*Stats, error) {
+	cs := s.stream(id)
+
+	// A freshly started stream has nothing yet; the first sample arrives
+	// almost immediately.
+	select {
+	case <-cs.ready:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for first stats sample for %s: %w", id, ctx.Err())
+	}
+
+	frame := cs.latest.Load()
+	if frame == nil {
+		return nil, fmt.Errorf("streaming stats for %s: %w", id, cs.firstErr)
+	}
+	if age := time.Since(frame.received); age > s.maxAge {
+		return nil, fmt.Errorf("streaming stats for %s: no sample for %s", id, age.Round(time.Second))
+	}
+	return ParseDockerStats(frame.stats, frame.inspect), nil
+}
+
+// Close stops all streams.
+func (s *StatsStreamer) Close() {
+	s.cancel()
+
+	s.mu.Lock()
+	s.streams = make(map[string]*containerStream)
+	s.mu.Unlock()
+}
+
+// stream returns the reader for id, starting it if needed.
+func (s *StatsStreamer) stream(id string) *containerStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cs, ok := s.streams[id]; ok {
+		return cs
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	cs := &containerStream{cancel: cancel, ready: make(chan struct{})}
+	s.streams[id] = cs
+	go s.follow(ctx, id, cs)
+
+	return cs
+}
+
+// follow keeps a stream open until ctx is canceled, reopening it with
+// backoff if the daemon closes it (e.g. while the container restarts).
+func (s *StatsStreamer) follow(ctx context.Context, id string, cs *containerStream) {
+	backoff := streamMinBackoff
+
+	for {
+		received, err := s.read(ctx, id, cs)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			backoff = streamMinBackoff
+		}
+		// Don't leave the first scrape waiting for a stream that can't open
+		cs.readyOnce.Do(func() {
+			cs.firstErr = err
+			close(cs.ready)
+		})
+		log.WithError(err).WithField("container", id).Debug("Stats stream closed, reopening")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, streamMaxBackoff)
+	}
+}
+
+// read consumes one stream until it ends. It reports whether any sample was
+// received, so follow can reset its backoff.
+func (s *StatsStreamer) read(ctx context.Context, id string, cs *containerStream) (bool, error) {
+	stream, err := s.source.OpenStatsStream(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	defer stream.Close()
+
+	// The container may have been updated or renamed while the stream was
+	// down; pair the last sample with the fresh inspect data right away.
+	if frame := cs.latest.Load(); frame != nil {
+		cs.latest.Store(&streamFrame{stats: frame.stats, inspect: stream.inspect, received: frame.received})
+	}
+
+	received := false
+	for {
+		statsJSON, err := stream.Next()
+		if err != nil {
+			return received, err
+		}
+		received = true
+		cs.latest.Store(&streamFrame{stats: statsJSON, inspect: stream.inspect, received: time.Now()})
+		cs.readyOnce.Do(func() { close(cs.ready) })
+	}
+}
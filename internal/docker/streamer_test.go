@@ -0,0 +1,225 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStreamSource hands out pipe-backed streams the test can write samples to.
+type fakeStreamSource struct {
+	mu      sync.Mutex
+	writers map[string]*io.PipeWriter
+	ctxs    map[string]context.Context
+	name    string // container name reported by the next open, if set
+	openErr error
+	opened  chan string
+}
+
+func newFakeStreamSource() *fakeStreamSource {
+	return &fakeStreamSource{
+		writers: make(map[string]*io.PipeWriter),
+		ctxs:    make(map[string]context.Context),
+		opened:  make(chan string, 10),
+	}
+}
+
+func (f *fakeStreamSource) OpenStatsStream(ctx context.Context, id string) (*StatsStream, error) {
+	if f.openErr != nil {
+		return nil, f.openErr
+	}
+	r, w := io.Pipe()
+	go func() {
+		<-ctx.Done()
+		w.CloseWithError(ctx.Err())
+	}()
+
+	inspect := testContainerJSON()
+	f.mu.Lock()
+	f.writers[id] = w
+	f.ctxs[id] = ctx
+	if f.name != "" {
+		inspect.Name = f.name
+	}
+	f.mu.Unlock()
+	f.opened <- id
+
+	return newStatsStream(r, inspect), nil
+}
+
+// end makes the daemon close the stream for id.
+func (f *fakeStreamSource) end(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writers[id].CloseWithError(io.EOF)
+}
+
+func (f *fakeStreamSource) send(t *testing.T, id string, cpuTotal uint64) {
+	t.Helper()
+	f.mu.Lock()
+	w := f.writers[id]
+	f.mu.Unlock()
+
+	var s types.StatsJSON
+	s.CPUStats.CPUUsage.TotalUsage = cpuTotal
+	assert.NoError(t, json.NewEncoder(w).Encode(&s))
+}
+
+type fakeLister struct {
+	containers []Container
+}
+
+func (f *fakeLister) ListContainers(_ context.Context) ([]Container, error) {
+	return f.containers, nil
+}
+
+func TestStatsStreamer_ServesLatestSample(t *testing.T) {
+	src := newFakeStreamSource()
+	streamer := newStatsStreamer(src, &fakeLister{}, time.Minute)
+	defer streamer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// First call starts the stream and waits for its first sample
+	go func() {
+		<-src.opened
+		src.send(t, "abc", 100)
+	}()
+	stats, err := streamer.GetContainerStats(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), stats.CPUUsageTotal)
+	assert.Equal(t, "test-container", stats.Name)
+
+	// Later calls read whatever the stream delivered last
+	src.send(t, "abc", 200)
+	src.send(t, "abc", 300)
+	assert.Eventually(t, func() bool {
+		stats, err := streamer.GetContainerStats(ctx, "abc")
+		return err == nil && stats.CPUUsageTotal == 300
+	}, 2*time.Second, 5*time.Millisecond)
+
+	select {
+	case id := <-src.opened:
+		t.Fatalf("unexpected second stream for %s", id)
+	default:
+	}
+}
+
+func TestStatsStreamer_StaleSample(t *testing.T) {
+	src := newFakeStreamSource()
+	streamer := newStatsStreamer(src, &fakeLister{}, 100*time.Millisecond)
+	defer streamer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		<-src.opened
+		src.send(t, "abc", 100)
+	}()
+	_, err := streamer.GetContainerStats(ctx, "abc")
+	require.NoError(t, err)
+
+	// The stream stays open but stops delivering
+	assert.Eventually(t, func() bool {
+		_, err := streamer.GetContainerStats(ctx, "abc")
+		return err != nil
+	}, 2*time.Second, 5*time.Millisecond, "stale sample should not be served")
+
+	src.send(t, "abc", 200)
+	assert.Eventually(t, func() bool {
+		stats, err := streamer.GetContainerStats(ctx, "abc")
+		return err == nil && stats.CPUUsageTotal == 200
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestStatsStreamer_ReopenRefreshesInspect(t *testing.T) {
+	src := newFakeStreamSource()
+	streamer := newStatsStreamer(src, &fakeLister{}, time.Minute)
+	defer streamer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		<-src.opened
+		src.send(t, "abc", 100)
+	}()
+	stats, err := streamer.GetContainerStats(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "test-container", stats.Name)
+
+	// Renamed while the stream was down: the reopened stream's inspect data
+	// applies before its first sample arrives
+	src.mu.Lock()
+	src.name = "/renamed"
+	src.mu.Unlock()
+	src.end("abc")
+
+	select {
+	case <-src.opened:
+	case <-ctx.Done():
+		t.Fatal("stream was not reopened")
+	}
+	assert.Eventually(t, func() bool {
+		stats, err := streamer.GetContainerStats(ctx, "abc")
+		return err == nil && stats.Name == "renamed"
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestStatsStreamer_OpenError(t *testing.T) {
+	src := newFakeStreamSource()
+	src.openErr = fmt.Errorf("no such container")
+	streamer := newStatsStreamer(src, &fakeLister{}, time.Minute)
+	defer streamer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := streamer.GetContainerStats(ctx, "gone")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no such container")
+	assert.NoError(t, ctx.Err(), "error should be returned without waiting for the deadline")
+}
+
+func TestStatsStreamer_StopsStreamsForStoppedContainers(t *testing.T) {
+	src := newFakeStreamSource()
+	lister := &fakeLister{containers: []Container{
+		{ID: "a", State: "running"},
+		{ID: "b", State: "running"},
+	}}
+	streamer := newStatsStreamer(src, lister, time.Minute)
+	defer streamer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, id := range []string{"a", "b"} {
+		go func() {
+			<-src.opened
+			src.send(t, id, 1)
+		}()
+		_, err := streamer.GetContainerStats(ctx, id)
+		require.NoError(t, err)
+	}
+
+	lister.containers = []Container{{ID: "a", State: "running"}, {ID: "b", State: "exited"}}
+	_, err := streamer.ListContainers(ctx)
+	require.NoError(t, err)
+
+	src.mu.Lock()
+	ctxA, ctxB := src.ctxs["a"], src.ctxs["b"]
+	src.mu.Unlock()
+
+	assert.Eventually(t, func() bool { return ctxB.Err() != nil }, 2*time.Second, 5*time.Millisecond, "stream for stopped container should be canceled")
+	assert.NoError(t, ctxA.Err(), "stream for running container should stay open")
+}
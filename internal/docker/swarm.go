@@ -0,0 +1,137 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// Service holds the parts of a swarm service the swarm collector reports.
+type Service struct {
+	ID     string
+	Name   string
+	Labels map[string]string
+	Mode   string // replicated, global, replicated-job, global-job
+
+	// DesiredTasks and RunningTasks come from the manager's service status.
+	DesiredTasks uint64
+	RunningTasks uint64
+
+	// UpdateState is the state of the last rolling update, empty if the
+	// service was never updated.
+	UpdateState string
+}
+
+// Task holds the parts of a swarm task the swarm collector reports.
+type Task struct {
+	ID        string
+	ServiceID string
+	NodeID    string
+	State     string
+}
+
+// Node holds the parts of a swarm node the swarm collector reports.
+type Node struct {
+	ID            string
+	Hostname      string
+	Role          string
+	Availability  string
+	State         string
+	EngineVersion string
+	Leader        bool
+}
+
+// SwarmState is one consistent-enough view of a swarm, as seen by a manager.
+type SwarmState struct {
+	Services []Service
+	Tasks    []Task
+	Nodes    []Node
+}
+
+// GetSwarmState lists services, tasks and nodes. Only managers can answer;
+// on a worker or a daemon outside a swarm this returns an error.
+func (c *Client) GetSwarmState(ctx context.Context) (*SwarmState, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	services, err := c.cli.ServiceList(ctx, types.ServiceListOptions{Status: true})
+	if err != nil {
+		return nil, fmt.Errorf("listing services: %w", err)
+	}
+
+	tasks, err := c.cli.TaskList(ctx, types.TaskListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing tasks: %w", err)
+	}
+
+	nodes, err := c.cli.NodeList(ctx, types.NodeListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+
+	state := &SwarmState{
+		Services: make([]Service, 0, len(services)),
+		Tasks:    make([]Task, 0, len(tasks)),
+		Nodes:    make([]Node, 0, len(nodes)),
+	}
+
+	for _, s := range services {
+		svc := Service{
+			ID:     s.ID,
+			Name:   s.Spec.Name,
+			Labels: s.Spec.Labels,
+			Mode:   serviceMode(s.Spec.Mode),
+		}
+		if s.ServiceStatus != nil {
+			svc.DesiredTasks = s.ServiceStatus.DesiredTasks
+			svc.RunningTasks = s.ServiceStatus.RunningTasks
+		}
+		if s.UpdateStatus != nil {
+			svc.UpdateState = string(s.UpdateStatus.State)
+		}
+		state.Services = append(state.Services, svc)
+	}
+
+	for _, t := range tasks {
+		state.Tasks = append(state.Tasks, Task{
+			ID:        t.ID,
+			ServiceID: t.ServiceID,
+			NodeID:    t.NodeID,
+			State:     string(t.Status.State),
+		})
+	}
+
+	for _, n := range nodes {
+		node := Node{
+			ID:            n.ID,
+			Hostname:      n.Description.Hostname,
+			Role:          string(n.Spec.Role),
+			Availability:  string(n.Spec.Availability),
+			State:         string(n.Status.State),
+			EngineVersion: n.Description.Engine.EngineVersion,
+		}
+		if n.ManagerStatus != nil {
+			node.Leader = n.ManagerStatus.Leader
+		}
+		state.Nodes = append(state.Nodes, node)
+	}
+
+	return state, nil
+}
+
+func serviceMode(m swarm.ServiceMode) string {
+	switch {
+	case m.Replicated != nil:
+		return "replicated"
+	case m.Global != nil:
+		return "global"
+	case m.ReplicatedJob != nil:
+		return "replicated-job"
+	case m.GlobalJob != nil:
+		return "global-job"
+	default:
+		return ""
+	}
+}
@@ -6,8 +6,16 @@ import "github.com/prometheus/client_golang/prometheus"
 var (
 	containerLabelNames = []string{"container_name", "compose_service", "compose_project", "image"}
 	networkLabelNames   = append(containerLabelNames, "interface")
-	blockIOLabelNames   = append(containerLabelNames, "device")
+	interfaceLabelNames = append(networkLabelNames, "network_name", "network_driver", "ip_address")
+	blockIOLabelNames   = append(containerLabelNames, "device", "device_name")
+	cpuLabelNames       = append(containerLabelNames, "cpu")
+	pressureLabelNames  = append(containerLabelNames, "resource", "kind")
 	infoLabelNames      = append(containerLabelNames, "container_id", "status", "health_status", "started_at")
+	serviceLabelNames   = []string{"service_name", "stack_namespace"}
+	imageLabelNames     = []string{"image_id", "repository", "tag"}
+
+	// Docker networks themselves, as opposed to a container's interfaces
+	dockerNetworkLabelNames = []string{"network_id", "network_name"}
 )
 
 // --- Memory metrics ---
@@ -48,6 +56,31 @@ var (
 		"Number of times memory limit was hit.",
 		containerLabelNames, nil,
 	)
+	MemoryUsageRatio = prometheus.NewDesc(
+		"container_memory_usage_ratio",
+		"Memory usage over the effective limit: the container's limit, or host memory when it has none.",
+		containerLabelNames, nil,
+	)
+	MemoryWorkingSetRatio = prometheus.NewDesc(
+		"container_memory_working_set_ratio",
+		"Working set over the effective limit: the container's limit, or host memory when it has none.",
+		containerLabelNames, nil,
+	)
+	MemoryUnlimited = prometheus.NewDesc(
+		"container_memory_unlimited",
+		"1 if the container has no memory limit, so the memory ratios are against host memory.",
+		containerLabelNames, nil,
+	)
+	MemoryHostShare = prometheus.NewDesc(
+		"container_memory_host_share_ratio",
+		"Working set as a share of host memory.",
+		containerLabelNames, nil,
+	)
+	MemoryStat = prometheus.NewDesc(
+		"container_memory_stat",
+		"Raw memory.stat value, under its cgroup v2 name. Byte counts except for event counters such as pgfault.",
+		append(containerLabelNames, "stat"), nil,
+	)
 )
 
 // --- CPU metrics (counters in nanoseconds, converted to seconds) ---
@@ -78,6 +111,26 @@ var (
 		"Total time throttled in seconds.",
 		containerLabelNames, nil,
 	)
+	CPUUsageRatio = prometheus.NewDesc(
+		"container_cpu_usage_ratio",
+		"CPU usage between the last two samples, in CPUs (docker stats CPU % divided by 100).",
+		containerLabelNames, nil,
+	)
+	CPULimitUsageRatio = prometheus.NewDesc(
+		"container_cpu_limit_usage_ratio",
+		"CPU usage between the last two samples as a share of the configured CPU limit (only for containers with a limit).",
+		containerLabelNames, nil,
+	)
+	CPUHostShare = prometheus.NewDesc(
+		"container_cpu_host_share_ratio",
+		"CPU usage between the last two samples as a share of all host CPUs.",
+		containerLabelNames, nil,
+	)
+	CPUUsagePerCPU = prometheus.NewDesc(
+		"container_cpu_usage_per_cpu_seconds_total",
+		"Cumulative CPU time consumed on each CPU in seconds.",
+		cpuLabelNames, nil,
+	)
 )
 
 // --- Network metrics ---
@@ -123,6 +176,11 @@ var (
 		"Total transmitted packets dropped.",
 		networkLabelNames, nil,
 	)
+	NetworkInterfaceInfo = prometheus.NewDesc(
+		"container_network_interface_info",
+		"Always 1; ties a container interface to the Docker network it is attached to.",
+		interfaceLabelNames, nil,
+	)
 )
 
 // --- Block I/O metrics ---
@@ -148,6 +206,171 @@ var (
 		"Total write operations.",
 		blockIOLabelNames, nil,
 	)
+	FSDiscardBytes = prometheus.NewDesc(
+		"container_fs_discards_bytes_total",
+		"Total bytes discarded.",
+		blockIOLabelNames, nil,
+	)
+	FSDiscardOps = prometheus.NewDesc(
+		"container_fs_discards_total",
+		"Total discard operations.",
+		blockIOLabelNames, nil,
+	)
+	FSReadTime = prometheus.NewDesc(
+		"container_fs_read_seconds_total",
+		"Time the device spent servicing reads, from dispatch to completion.",
+		blockIOLabelNames, nil,
+	)
+	FSWriteTime = prometheus.NewDesc(
+		"container_fs_write_seconds_total",
+		"Time the device spent servicing writes, from dispatch to completion.",
+		blockIOLabelNames, nil,
+	)
+	FSReadWaitTime = prometheus.NewDesc(
+		"container_fs_read_wait_seconds_total",
+		"Time reads spent waiting in the scheduler queue.",
+		blockIOLabelNames, nil,
+	)
+	FSWriteWaitTime = prometheus.NewDesc(
+		"container_fs_write_wait_seconds_total",
+		"Time writes spent waiting in the scheduler queue.",
+		blockIOLabelNames, nil,
+	)
+	FSReadsMerged = prometheus.NewDesc(
+		"container_fs_reads_merged_total",
+		"Total reads merged into other requests.",
+		blockIOLabelNames, nil,
+	)
+	FSWritesMerged = prometheus.NewDesc(
+		"container_fs_writes_merged_total",
+		"Total writes merged into other requests.",
+		blockIOLabelNames, nil,
+	)
+	FSIOCurrent = prometheus.NewDesc(
+		"container_fs_io_current",
+		"Requests currently queued.",
+		blockIOLabelNames, nil,
+	)
+	FSIOTime = prometheus.NewDesc(
+		"container_fs_io_time_seconds_total",
+		"Disk time allocated to the container.",
+		blockIOLabelNames, nil,
+	)
+)
+
+// --- Filesystem size metrics (refreshed in the background) ---
+
+var (
+	FSWritableLayer = prometheus.NewDesc(
+		"container_fs_writable_layer_bytes",
+		"Size of the files the container created or changed in its writable layer.",
+		containerLabelNames, nil,
+	)
+	FSRootfs = prometheus.NewDesc(
+		"container_fs_rootfs_bytes",
+		"Total size of the container's root filesystem, image layers included.",
+		containerLabelNames, nil,
+	)
+	FSSizeAge = prometheus.NewDesc(
+		"container_fs_size_age_seconds",
+		"Seconds since container filesystem sizes were last refreshed successfully.",
+		nil, nil,
+	)
+)
+
+// --- Pressure stall information (cgroup v2) ---
+
+var (
+	PressureStalled = prometheus.NewDesc(
+		"container_pressure_stalled_seconds_total",
+		"Time some (kind=some) or all (kind=full) of the container's tasks were stalled on a resource.",
+		pressureLabelNames, nil,
+	)
+	PressureAvg = prometheus.NewDesc(
+		"container_pressure_stall_ratio",
+		"Share of wall time tasks were stalled on a resource, averaged over the window (10s, 60s or 300s).",
+		append(pressureLabelNames, "window"), nil,
+	)
+)
+
+// --- Sockets (from the container's network namespace) ---
+
+var (
+	NetworkTCPConnections = prometheus.NewDesc(
+		"container_network_tcp_connections",
+		"TCP connections (IPv4 and IPv6) in the container's network namespace, by state.",
+		append(containerLabelNames, "state"), nil,
+	)
+	NetworkUDPSockets = prometheus.NewDesc(
+		"container_network_udp_sockets",
+		"UDP sockets (IPv4 and IPv6) in the container's network namespace.",
+		containerLabelNames, nil,
+	)
+	NetworkSockets = prometheus.NewDesc(
+		"container_network_sockets",
+		"Socket counters from /proc/net/sockstat in the container's network namespace (kind: inuse, orphan, tw, alloc).",
+		append(containerLabelNames, "protocol", "kind"), nil,
+	)
+)
+
+// --- Configured limits (from HostConfig; 0 means not set) ---
+
+var (
+	SpecCPULimit = prometheus.NewDesc(
+		"container_spec_cpu_limit_cores",
+		"CPU limit in cores, from --cpus or the CFS quota (0 if unlimited).",
+		containerLabelNames, nil,
+	)
+	SpecCPUQuota = prometheus.NewDesc(
+		"container_spec_cpu_quota_microseconds",
+		"CFS quota per period in microseconds.",
+		containerLabelNames, nil,
+	)
+	SpecCPUPeriod = prometheus.NewDesc(
+		"container_spec_cpu_period_microseconds",
+		"CFS period in microseconds.",
+		containerLabelNames, nil,
+	)
+	SpecCPUShares = prometheus.NewDesc(
+		"container_spec_cpu_shares",
+		"CPU shares (relative weight).",
+		containerLabelNames, nil,
+	)
+	SpecCpusetCPUs = prometheus.NewDesc(
+		"container_spec_cpuset_cpus",
+		"Number of CPUs in the container's cpuset (0 if not pinned).",
+		containerLabelNames, nil,
+	)
+	SpecMemoryLimit = prometheus.NewDesc(
+		"container_spec_memory_limit_bytes",
+		"Configured memory limit in bytes.",
+		containerLabelNames, nil,
+	)
+	SpecMemoryReservation = prometheus.NewDesc(
+		"container_spec_memory_reservation_bytes",
+		"Configured memory reservation (soft limit) in bytes.",
+		containerLabelNames, nil,
+	)
+	SpecMemorySwapLimit = prometheus.NewDesc(
+		"container_spec_memory_swap_limit_bytes",
+		"Configured memory+swap limit in bytes (-1 if unlimited).",
+		containerLabelNames, nil,
+	)
+	SpecPIDsLimit = prometheus.NewDesc(
+		"container_spec_pids_limit",
+		"Configured PIDs limit.",
+		containerLabelNames, nil,
+	)
+	SpecRestartPolicy = prometheus.NewDesc(
+		"container_spec_restart_policy",
+		"Restart policy (value always 1).",
+		append(containerLabelNames, "policy"), nil,
+	)
+	SpecRestartMaxRetries = prometheus.NewDesc(
+		"container_spec_restart_max_retries",
+		"Maximum restart attempts for the on-failure policy.",
+		containerLabelNames, nil,
+	)
 )
 
 // --- Process metrics ---
@@ -198,6 +421,21 @@ var (
 		"Last exit code of the container.",
 		containerLabelNames, nil,
 	)
+	ContainerOOMKilled = prometheus.NewDesc(
+		"container_oom_killed",
+		"Whether the container's last exit was an OOM kill (1) or not (0).",
+		containerLabelNames, nil,
+	)
+	ContainerOOMEvents = prometheus.NewDesc(
+		"container_oom_events_total",
+		"OOM kill events seen for the container since the exporter started.",
+		containerLabelNames, nil,
+	)
+	ContainerEvents = prometheus.NewDesc(
+		"docker_container_events_total",
+		"Container lifecycle events seen since the exporter started, by action and compose service.",
+		[]string{"action", "compose_service", "compose_project"}, nil,
+	)
 )
 
 // --- System metrics ---
@@ -223,6 +461,141 @@ var (
 		"Total number of networks.",
 		nil, nil,
 	)
+	DockerHostMemory = prometheus.NewDesc(
+		"docker_host_memory_bytes",
+		"Total memory of the daemon's host.",
+		nil, nil,
+	)
+	DockerHostCPUs = prometheus.NewDesc(
+		"docker_host_cpus",
+		"Number of CPUs of the daemon's host.",
+		nil, nil,
+	)
+)
+
+// --- Image metrics ---
+
+var (
+	ImageSize = prometheus.NewDesc(
+		"docker_image_size_bytes",
+		"Total size of the image, including layers shared with other images.",
+		imageLabelNames, nil,
+	)
+	ImageSharedSize = prometheus.NewDesc(
+		"docker_image_shared_size_bytes",
+		"Size of the image layers shared with other images.",
+		imageLabelNames, nil,
+	)
+	ImageCreated = prometheus.NewDesc(
+		"docker_image_created_timestamp_seconds",
+		"Unix timestamp when the image was created.",
+		imageLabelNames, nil,
+	)
+	ImageContainers = prometheus.NewDesc(
+		"docker_image_containers",
+		"Number of containers, running or stopped, using the image.",
+		imageLabelNames, nil,
+	)
+	ImageDangling = prometheus.NewDesc(
+		"docker_image_dangling",
+		"Whether the image has no tag (1) or not (0).",
+		imageLabelNames, nil,
+	)
+	ImagesDropped = prometheus.NewDesc(
+		"docker_images_dropped",
+		"Number of images not reported because of collection.images.max_images.",
+		nil, nil,
+	)
+)
+
+// --- Docker network metrics ---
+
+var (
+	DockerNetworkInfo = prometheus.NewDesc(
+		"docker_network_info",
+		"Docker network driver, scope and options (always 1).",
+		append(dockerNetworkLabelNames, "driver", "scope", "internal", "attachable"), nil,
+	)
+	DockerNetworkContainers = prometheus.NewDesc(
+		"docker_network_containers",
+		"Number of running containers attached to the network.",
+		dockerNetworkLabelNames, nil,
+	)
+	DockerNetworkSubnetAddresses = prometheus.NewDesc(
+		"docker_network_subnet_addresses",
+		"Number of addresses IPAM can allocate in the subnet, or its IP range if set.",
+		append(dockerNetworkLabelNames, "subnet"), nil,
+	)
+	DockerNetworkSubnetAllocated = prometheus.NewDesc(
+		"docker_network_subnet_allocated_addresses",
+		"Number of allocatable addresses in use by endpoints, the gateway and auxiliary addresses.",
+		append(dockerNetworkLabelNames, "subnet"), nil,
+	)
+)
+
+// --- Disk usage metrics ---
+
+var (
+	DiskUsageBytes = prometheus.NewDesc(
+		"docker_disk_usage_bytes",
+		"Disk space used, by object type (images, containers, volumes, build_cache).",
+		[]string{"type"}, nil,
+	)
+	DiskUsageReclaimable = prometheus.NewDesc(
+		"docker_disk_usage_reclaimable_bytes",
+		"Disk space a prune could free, by object type.",
+		[]string{"type"}, nil,
+	)
+	DiskUsageObjects = prometheus.NewDesc(
+		"docker_disk_usage_objects",
+		"Number of objects, by object type and whether they are active (in use).",
+		[]string{"type", "active"}, nil,
+	)
+	DiskUsageAge = prometheus.NewDesc(
+		"docker_disk_usage_age_seconds",
+		"Seconds since the disk usage data was last refreshed successfully.",
+		nil, nil,
+	)
+	VolumeSize = prometheus.NewDesc(
+		"docker_volume_size_bytes",
+		"Disk space used by the volume (local driver only).",
+		[]string{"volume_name", "driver"}, nil,
+	)
+	VolumeRefCount = prometheus.NewDesc(
+		"docker_volume_ref_count",
+		"Number of containers referencing the volume.",
+		[]string{"volume_name", "driver"}, nil,
+	)
+)
+
+// --- Swarm metrics ---
+
+var (
+	SwarmServiceReplicasDesired = prometheus.NewDesc(
+		"swarm_service_replicas_desired",
+		"Number of tasks the service should be running.",
+		append(serviceLabelNames, "mode"), nil,
+	)
+	SwarmServiceReplicasRunning = prometheus.NewDesc(
+		"swarm_service_replicas_running",
+		"Number of service tasks currently running.",
+		append(serviceLabelNames, "mode"), nil,
+	)
+	SwarmServiceTasks = prometheus.NewDesc(
+		"swarm_service_tasks",
+		"Number of service tasks by current state, including retained history.",
+		append(serviceLabelNames, "state"), nil,
+	)
+	SwarmServiceUpdateState = prometheus.NewDesc(
+		"swarm_service_update_state",
+		"State of the service's last rolling update (1 for the current state, none if never updated).",
+		append(serviceLabelNames, "state"), nil,
+	)
+	SwarmNodeInfo = prometheus.NewDesc(
+		"swarm_node_info",
+		"Swarm node role, availability and status (always 1).",
+		[]string{"node_id", "hostname", "role", "availability", "state", "leader", "engine_version"}, nil,
+	)
 )
 
 // --- Exporter self-metrics ---
@@ -248,19 +621,82 @@ var (
 		"Whether the exporter is up.",
 		nil, nil,
 	)
+	ExporterSnapshotAge = prometheus.NewDesc(
+		"exporter_snapshot_age_seconds",
+		"Age of the background collection snapshot served to scrapes.",
+		nil, nil,
+	)
+	ProbeSuccess = prometheus.NewDesc(
+		"probe_success",
+		"Whether the probed Docker daemon answered a ping.",
+		nil, nil,
+	)
 )
 
 // AllContainerDescs returns all metric descriptors for the container collector.
 func AllContainerDescs() []*prometheus.Desc {
 	return []*prometheus.Desc{
-		MemoryUsage, MemoryLimit, MemoryCache, MemoryRSS, MemorySwap, MemoryWorkingSet, MemoryFailcnt,
-		CPUUsageTotal, CPUUsageSystem, CPUUsageUser, CPUThrottledPeriods, CPUThrottledTime,
+		MemoryUsage, MemoryLimit, MemoryCache, MemoryRSS, MemorySwap, MemoryWorkingSet, MemoryFailcnt, MemoryStat,
+		MemoryUsageRatio, MemoryWorkingSetRatio, MemoryUnlimited, MemoryHostShare,
+		CPUUsageTotal, CPUUsageSystem, CPUUsageUser, CPUThrottledPeriods, CPUThrottledTime, CPUUsagePerCPU,
+		CPUUsageRatio, CPULimitUsageRatio, CPUHostShare,
 		NetworkRxBytes, NetworkTxBytes, NetworkRxPackets, NetworkTxPackets,
-		NetworkRxErrors, NetworkTxErrors, NetworkRxDropped, NetworkTxDropped,
-		FSReadBytes, FSWriteBytes, FSReadOps, FSWriteOps,
+		NetworkRxErrors, NetworkTxErrors, NetworkRxDropped, NetworkTxDropped, NetworkInterfaceInfo,
+		FSReadBytes, FSWriteBytes, FSReadOps, FSWriteOps, FSDiscardBytes, FSDiscardOps,
+		FSReadTime, FSWriteTime, FSReadWaitTime, FSWriteWaitTime, FSReadsMerged, FSWritesMerged, FSIOCurrent, FSIOTime,
 		PIDsCurrent,
 		ContainerLastSeen, ContainerStartTime, ContainerUptime, ContainerInfo,
-		ContainerHealthStatus, ContainerRestartCount, ContainerExitCode,
+		ContainerHealthStatus, ContainerRestartCount, ContainerExitCode, ContainerOOMKilled,
+		SpecCPULimit, SpecCPUQuota, SpecCPUPeriod, SpecCPUShares, SpecCpusetCPUs,
+		SpecMemoryLimit, SpecMemoryReservation, SpecMemorySwapLimit, SpecPIDsLimit,
+		SpecRestartPolicy, SpecRestartMaxRetries,
+	}
+}
+
+// AllImageDescs returns all metric descriptors for the image collector.
+func AllImageDescs() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		ImageSize, ImageSharedSize, ImageCreated, ImageContainers, ImageDangling, ImagesDropped,
+	}
+}
+
+// AllDockerNetworkDescs returns all metric descriptors for the network collector.
+func AllDockerNetworkDescs() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		DockerNetworkInfo, DockerNetworkContainers,
+		DockerNetworkSubnetAddresses, DockerNetworkSubnetAllocated,
+	}
+}
+
+// AllContainerSizeDescs returns all metric descriptors for the container size
+// collector.
+func AllContainerSizeDescs() []*prometheus.Desc {
+	return []*prometheus.Desc{FSWritableLayer, FSRootfs, FSSizeAge}
+}
+
+// AllPressureDescs returns all metric descriptors for the pressure collector.
+func AllPressureDescs() []*prometheus.Desc {
+	return []*prometheus.Desc{PressureStalled, PressureAvg}
+}
+
+// AllSocketDescs returns all metric descriptors for the socket collector.
+func AllSocketDescs() []*prometheus.Desc {
+	return []*prometheus.Desc{NetworkTCPConnections, NetworkUDPSockets, NetworkSockets}
+}
+
+// AllDiskUsageDescs returns all metric descriptors for the disk usage collector.
+func AllDiskUsageDescs() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		DiskUsageBytes, DiskUsageReclaimable, DiskUsageObjects, DiskUsageAge,
+		VolumeSize, VolumeRefCount,
+	}
+}
+
+// AllSwarmDescs returns all metric descriptors for the swarm collector.
+func AllSwarmDescs() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		SwarmServiceReplicasDesired, SwarmServiceReplicasRunning, SwarmServiceTasks,
+		SwarmServiceUpdateState, SwarmNodeInfo,
 	}
 }
 
@@ -268,6 +704,7 @@ func AllContainerDescs() []*prometheus.Desc {
 func AllSystemDescs() []*prometheus.Desc {
 	return []*prometheus.Desc{
 		DockerContainersTotal, DockerImagesTotal, DockerVolumesTotal, DockerNetworksTotal,
+		DockerHostMemory, DockerHostCPUs,
 		ExporterBuildInfo, ExporterUp, ExporterScrapeDuration, ExporterScrapeErrors,
 	}
 }
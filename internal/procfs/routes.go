@@ -0,0 +1,152 @@
+package procfs
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// InterfaceSubnets returns the directly connected subnets of each interface
+// in the network namespace pid belongs to, from its IPv4 and IPv6 routing
+// tables. Docker adds one such route per network a container is attached
+// to, which ties an interface name to a network's subnet. A missing IPv6
+// table, as on hosts with IPv6 disabled, is skipped.
+func (r *Reader) InterfaceSubnets(pid int) (map[string][]*net.IPNet, error) {
+	dir := filepath.Join(r.root, strconv.Itoa(pid), "net")
+	subnets := make(map[string][]*net.IPNet)
+	if err := readRoutes(filepath.Join(dir, "route"), subnets); err != nil {
+		return nil, err
+	}
+	if err := readRoutes6(filepath.Join(dir, "ipv6_route"), subnets); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return subnets, nil
+}
+
+// InterfaceMACs returns the MAC address of each interface in the network
+// namespace pid belongs to, read from the sysfs mounted in its root
+// filesystem. Docker mounts a fresh sysfs in every container with its own
+// network namespace, so the listing matches the container's interfaces.
+func (r *Reader) InterfaceMACs(pid int) (map[string]string, error) {
+	dir := filepath.Join(r.root, strconv.Itoa(pid), "root", "sys", "class", "net")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	macs := make(map[string]string, len(entries))
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, e.Name(), "address"))
+		if err != nil {
+			continue // raced with the interface going away
+		}
+		macs[e.Name()] = strings.TrimSpace(string(b))
+	}
+	return macs, nil
+}
+
+// readRoutes adds the directly connected subnets of /proc/net/route to
+// subnets. The default route and routes through a gateway are skipped.
+//
+//	Iface Destination Gateway Flags RefCnt Use Metric Mask MTU Window IRTT
+func readRoutes(path string, subnets map[string][]*net.IPNet) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+		dst, err := parseRouteAddr(fields[1])
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		gw, err := parseRouteAddr(fields[2])
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		mask, err := parseRouteAddr(fields[7])
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+
+		if !gw.IsUnspecified() || mask.IsUnspecified() {
+			continue
+		}
+		subnets[fields[0]] = append(subnets[fields[0]], &net.IPNet{IP: dst, Mask: net.IPMask(mask)})
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
+
+// readRoutes6 adds the directly connected subnets of /proc/net/ipv6_route
+// to subnets. It has no header, and prints addresses in network byte order.
+// Besides the default route and routes through a gateway, link-local,
+// loopback and multicast routes are skipped: Docker never assigns endpoints
+// such addresses.
+//
+//	dst dst_len src src_len next_hop metric refcnt use flags iface
+func readRoutes6(path string, subnets map[string][]*net.IPNet) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		dst, err := hex.DecodeString(fields[0])
+		if err != nil || len(dst) != net.IPv6len {
+			return fmt.Errorf("parsing %s: malformed address %q", path, fields[0])
+		}
+		bits, err := strconv.ParseUint(fields[1], 16, 8)
+		if err != nil || bits > 128 {
+			return fmt.Errorf("parsing %s: malformed prefix length %q", path, fields[1])
+		}
+		gw, err := hex.DecodeString(fields[4])
+		if err != nil || len(gw) != net.IPv6len {
+			return fmt.Errorf("parsing %s: malformed address %q", path, fields[4])
+		}
+
+		ip := net.IP(dst)
+		if !net.IP(gw).IsUnspecified() || bits == 0 || ip.IsLinkLocalUnicast() || ip.IsLoopback() || ip.IsMulticast() {
+			continue
+		}
+		subnets[fields[9]] = append(subnets[fields[9]], &net.IPNet{IP: ip, Mask: net.CIDRMask(int(bits), 128)})
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
+
+// parseRouteAddr decodes an address from /proc/net/route, which prints the
+// IPv4 address word in host byte order ("0100A8C0" is 192.168.0.1 on
+// little-endian machines).
+func parseRouteAddr(s string) (net.IP, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 4 {
+		return nil, fmt.Errorf("malformed address %q", s)
+	}
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, binary.NativeEndian.Uint32(b))
+	return ip, nil
+}
@@ -0,0 +1,49 @@
+package procfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader_InterfaceSubnets(t *testing.T) {
+	subnets, err := NewReader(testRoot).InterfaceSubnets(4242)
+	require.NoError(t, err)
+
+	require.Len(t, subnets["eth0"], 1, "the default route is skipped")
+	assert.Equal(t, "172.17.0.0/16", subnets["eth0"][0].String())
+	require.Len(t, subnets["eth1"], 1)
+	assert.Equal(t, "172.20.0.0/24", subnets["eth1"][0].String())
+	require.Len(t, subnets["eth2"], 1, "IPv6-only network; link-local, loopback and multicast routes are skipped")
+	assert.Equal(t, "fd00:0:0:2::/64", subnets["eth2"][0].String())
+	assert.NotContains(t, subnets, "lo")
+
+	_, err = NewReader(testRoot).InterfaceSubnets(5151)
+	assert.Error(t, err)
+}
+
+func TestParseRouteAddr(t *testing.T) {
+	ip, err := parseRouteAddr("0100A8C0")
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.0.1", ip.String())
+
+	_, err = parseRouteAddr("0100A8")
+	assert.Error(t, err)
+	_, err = parseRouteAddr("zz00A8C0")
+	assert.Error(t, err)
+}
+
+func TestReader_InterfaceMACs(t *testing.T) {
+	macs, err := NewReader(testRoot).InterfaceMACs(4242)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"eth0": "02:42:ac:11:00:02",
+		"eth1": "02:42:ac:14:00:05",
+		"eth2": "02:42:0a:00:02:03",
+		"lo":   "00:00:00:00:00:00",
+	}, macs)
+
+	_, err = NewReader(testRoot).InterfaceMACs(5151)
+	assert.Error(t, err)
+}
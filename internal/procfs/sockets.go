@@ -0,0 +1,181 @@
+// Package procfs reads per-process network state from a host /proc mount.
+// /proc/<pid>/net shows the network namespace of the process, so reading it
+// for a container's init process gives the container's socket tables without
+// entering the namespace.
+package procfs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// TCPStates are the connection states reported in /proc/net/tcp, indexed by
+// the kernel's state number (include/net/tcp_states.h).
+var TCPStates = []string{
+	1:  "established",
+	2:  "syn_sent",
+	3:  "syn_recv",
+	4:  "fin_wait1",
+	5:  "fin_wait2",
+	6:  "time_wait",
+	7:  "close",
+	8:  "close_wait",
+	9:  "last_ack",
+	10: "listen",
+	11: "closing",
+}
+
+// Sockets summarizes the socket tables of one network namespace.
+type Sockets struct {
+	// TCP counts IPv4 and IPv6 connections by state name.
+	TCP map[string]uint64
+	// UDP counts IPv4 and IPv6 sockets.
+	UDP uint64
+	// Sockstat holds the per-protocol counters of /proc/net/sockstat and
+	// sockstat6, keyed by lowercase protocol ("tcp", "udp6", ...) and then
+	// counter ("inuse", "orphan", "tw", "alloc"). Memory counters are left
+	// out.
+	Sockstat map[string]map[string]uint64
+}
+
+// Reader reads from a proc filesystem mounted at root (usually the host's
+// /proc, bind-mounted into the exporter).
+type Reader struct {
+	root string
+}
+
+// NewReader creates a reader for the proc filesystem at root.
+func NewReader(root string) *Reader {
+	return &Reader{root: root}
+}
+
+// NetNS returns the inode of the network namespace of pid, which identifies
+// the namespace across processes.
+func (r *Reader) NetNS(pid int) (uint64, error) {
+	// The link reads "net:[4026532281]"
+	link, err := os.Readlink(filepath.Join(r.root, strconv.Itoa(pid), "ns", "net"))
+	if err != nil {
+		return 0, err
+	}
+	inode, ok := strings.CutPrefix(link, "net:[")
+	if !ok || !strings.HasSuffix(inode, "]") {
+		return 0, fmt.Errorf("unexpected network namespace link %q", link)
+	}
+	return strconv.ParseUint(strings.TrimSuffix(inode, "]"), 10, 64)
+}
+
+// Sockets reads the TCP and UDP tables and socket counters of the network
+// namespace pid belongs to. Missing IPv6 files, as on hosts with IPv6
+// disabled, are skipped.
+func (r *Reader) Sockets(pid int) (*Sockets, error) {
+	dir := filepath.Join(r.root, strconv.Itoa(pid), "net")
+	s := &Sockets{
+		TCP:      make(map[string]uint64, len(TCPStates)),
+		Sockstat: make(map[string]map[string]uint64),
+	}
+
+	for _, name := range []string{"tcp", "tcp6"} {
+		err := readTable(filepath.Join(dir, name), func(state uint64) {
+			if state < uint64(len(TCPStates)) && TCPStates[state] != "" {
+				s.TCP[TCPStates[state]]++
+			}
+		})
+		if err != nil && (name == "tcp" || !os.IsNotExist(err)) {
+			return nil, err
+		}
+	}
+	for _, name := range []string{"udp", "udp6"} {
+		err := readTable(filepath.Join(dir, name), func(uint64) { s.UDP++ })
+		if err != nil && (name == "udp" || !os.IsNotExist(err)) {
+			return nil, err
+		}
+	}
+	for _, name := range []string{"sockstat", "sockstat6"} {
+		err := readSockstat(filepath.Join(dir, name), s.Sockstat)
+		if err != nil && (name == "sockstat" || !os.IsNotExist(err)) {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// readTable calls fn with the state of every socket in a /proc/net/{tcp,udp}
+// table. Only the state column is parsed: busy containers can hold tens of
+// thousands of connections.
+//
+//	sl  local_address rem_address   st tx_queue rx_queue ...
+//	 0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 ...
+func readTable(path string, fn func(state uint64)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		state, err := strconv.ParseUint(fields[3], 16, 8)
+		if err != nil {
+			return fmt.Errorf("parsing %s: state %q: %w", path, fields[3], err)
+		}
+		fn(state)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
+
+// readSockstat adds the counters of a sockstat file to dst. The global
+// "sockets: used" line and memory counters are skipped.
+//
+//	TCP: inuse 5 orphan 0 tw 2 alloc 7 mem 1
+//	UDP: inuse 1 mem 0
+func readSockstat(path string, dst map[string]map[string]uint64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return parseSockstat(f, dst)
+}
+
+func parseSockstat(r io.Reader, dst map[string]map[string]uint64) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		proto, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || proto == "sockets" {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields)%2 != 0 {
+			return fmt.Errorf("malformed sockstat line %q", scanner.Text())
+		}
+
+		counters := make(map[string]uint64, len(fields)/2)
+		for i := 0; i < len(fields); i += 2 {
+			if fields[i] == "mem" || fields[i] == "memory" {
+				continue
+			}
+			v, err := strconv.ParseUint(fields[i+1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("sockstat %s %s: %w", proto, fields[i], err)
+			}
+			counters[fields[i]] = v
+		}
+		if len(counters) > 0 {
+			dst[strings.ToLower(proto)] = counters
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,57 @@
+package procfs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRoot = "../../testdata/proc"
+
+func TestReader_Sockets(t *testing.T) {
+	s, err := NewReader(testRoot).Sockets(4242)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]uint64{
+		"listen":      2, // one IPv4, one IPv6
+		"established": 1,
+		"time_wait":   2,
+		"close_wait":  1,
+	}, s.TCP)
+	assert.Equal(t, uint64(1), s.UDP)
+
+	assert.Equal(t, map[string]uint64{"inuse": 3, "orphan": 0, "tw": 2, "alloc": 4}, s.Sockstat["tcp"])
+	assert.Equal(t, map[string]uint64{"inuse": 1}, s.Sockstat["tcp6"])
+	assert.Equal(t, map[string]uint64{"inuse": 0}, s.Sockstat["frag"], "memory counters are dropped")
+	assert.NotContains(t, s.Sockstat, "sockets", "sockets: used is host-wide")
+}
+
+func TestReader_SocketsWithoutIPv6(t *testing.T) {
+	s, err := NewReader(testRoot).Sockets(5151)
+	require.NoError(t, err)
+	assert.Empty(t, s.TCP)
+	assert.Zero(t, s.UDP)
+	assert.NotContains(t, s.Sockstat, "tcp6")
+
+	_, err = NewReader(testRoot).Sockets(1)
+	assert.Error(t, err, "process gone")
+}
+
+func TestReader_NetNS(t *testing.T) {
+	r := NewReader(testRoot)
+
+	inode, err := r.NetNS(4242)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4026532281), inode)
+
+	_, err = r.NetNS(5151)
+	assert.Error(t, err)
+}
+
+func TestParseSockstat(t *testing.T) {
+	dst := map[string]map[string]uint64{}
+	assert.Error(t, parseSockstat(strings.NewReader("TCP: inuse\n"), dst))
+	assert.Error(t, parseSockstat(strings.NewReader("TCP: inuse x\n"), dst))
+}
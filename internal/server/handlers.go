@@ -17,17 +17,22 @@ func healthHandler() http.HandlerFunc {
 	}
 }
 
-func readyHandler(client *docker.Client) http.HandlerFunc {
+// readyHandler reports ready as long as at least one Docker daemon answers,
+// so a single unreachable endpoint doesn't take the whole exporter out of
+// rotation.
+func readyHandler(clients []*docker.Client) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if err := client.Ping(r.Context()); err != nil {
-			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-			w.WriteHeader(http.StatusServiceUnavailable)
-			w.Write([]byte("NOT READY"))
-			return
+		for _, client := range clients {
+			if err := client.Ping(r.Context()); err == nil {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("READY"))
+				return
+			}
 		}
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("READY"))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("NOT READY"))
 	}
 }
 
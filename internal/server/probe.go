@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/collector"
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/internal/metrics"
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+// ProbeHandler serves /probe?target=<docker host>&module=<name>, blackbox
+// exporter style: it collects from the target daemon into a per-request
+// registry using a pooled client, with the TLS settings and filters of the
+// named module.
+type ProbeHandler struct {
+	cfg     *config.Config
+	pool    *docker.ClientPool
+	filters map[string]*docker.Filter
+	global  *docker.Filter
+	images  *docker.ImageFilter
+}
+
+// NewProbeHandler compiles the filters of every probe module. Returns an error
+// if any pattern is invalid.
+func NewProbeHandler(cfg *config.Config, pool *docker.ClientPool) (*ProbeHandler, error) {
+	global, err := docker.NewFilter(cfg.Collection.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("creating container filter: %w", err)
+	}
+
+	images, err := docker.NewImageFilter(cfg.Collection.Images)
+	if err != nil {
+		return nil, fmt.Errorf("creating image filter: %w", err)
+	}
+
+	filters := make(map[string]*docker.Filter)
+	for name, module := range cfg.Probe.Modules {
+		if module.Filters == nil {
+			continue
+		}
+		f, err := docker.NewFilter(*module.Filters)
+		if err != nil {
+			return nil, fmt.Errorf("creating filter for probe module %q: %w", name, err)
+		}
+		filters[name] = f
+	}
+
+	return &ProbeHandler{
+		cfg:     cfg,
+		pool:    pool,
+		filters: filters,
+		global:  global,
+		images:  images,
+	}, nil
+}
+
+// ServeHTTP probes the target and writes its metrics.
+func (h *ProbeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	target := params.Get("target")
+	if target == "" {
+		http.Error(w, "target parameter is missing", http.StatusBadRequest)
+		return
+	}
+
+	// Module names are lowercased when the config is loaded
+	moduleName := strings.ToLower(params.Get("module"))
+	if moduleName == "" {
+		moduleName = config.DefaultProbeModule
+	}
+	module, ok := h.cfg.Probe.Modules[moduleName]
+	if !ok && moduleName != config.DefaultProbeModule {
+		http.Error(w, fmt.Sprintf("unknown module %q", moduleName), http.StatusBadRequest)
+		return
+	}
+
+	filter, ok := h.filters[moduleName]
+	if !ok {
+		filter = h.global
+	}
+
+	client, release, err := h.pool.Get(moduleName+"|"+target, module.DockerConfig(target))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid target %q: %v", target, err), http.StatusBadRequest)
+		return
+	}
+	defer release()
+
+	logger := log.WithFields(log.Fields{"target": target, "module": moduleName})
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Collection.Timeout)
+	defer cancel()
+
+	registry := prometheus.NewRegistry()
+	err = client.Ping(ctx)
+	registry.MustRegister(probeStatus{success: err == nil})
+
+	// An unreachable daemon only reports probe_success 0 rather than making
+	// every collector wait out its timeout.
+	if err != nil {
+		logger.WithError(err).Warn("Probe target not reachable")
+	} else {
+		if h.cfg.Collection.Collectors.Container {
+			cache := collector.NewStatsCache(0, false)
+			registry.MustRegister(collector.NewContainerCollector(client, filter, cache, nil, docker.NewHostResources(client), nil, h.cfg))
+		}
+		if h.cfg.Collection.Collectors.System {
+			registry.MustRegister(collector.NewSystemCollector(client, h.cfg))
+		}
+		if h.cfg.Collection.Collectors.Swarm {
+			registry.MustRegister(collector.NewSwarmCollector(client, h.cfg))
+		}
+		if h.cfg.Collection.Collectors.Images {
+			registry.MustRegister(collector.NewImageCollector(client, h.images, h.cfg))
+		}
+		if h.cfg.Collection.Collectors.Networks {
+			registry.MustRegister(collector.NewNetworkCollector(client, h.cfg))
+		}
+	}
+
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	}).ServeHTTP(w, r)
+}
+
+// probeStatus emits probe_success for one probe request.
+type probeStatus struct {
+	success bool
+}
+
+func (p probeStatus) Describe(ch chan<- *prometheus.Desc) {
+	ch <- metrics.ProbeSuccess
+}
+
+func (p probeStatus) Collect(ch chan<- prometheus.Metric) {
+	var v float64
+	if p.success {
+		v = 1
+	}
+	metrics.SendSafe(ch, metrics.SafeNewConstMetric(metrics.ProbeSuccess, prometheus.GaugeValue, v))
+}
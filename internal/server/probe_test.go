@@ -0,0 +1,104 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fabienpiette/docker-stats-exporter/internal/docker"
+	"github.com/fabienpiette/docker-stats-exporter/pkg/config"
+)
+
+func newProbeTestHandler(t *testing.T) *ProbeHandler {
+	t.Helper()
+	cfg := &config.Config{
+		Collection: config.CollectionConfig{
+			Timeout:    2 * time.Second,
+			Collectors: config.CollectorsConfig{Container: true},
+		},
+		Performance: config.PerformanceConfig{MaxConcurrent: 2},
+		Probe: config.ProbeConfig{
+			Enabled: true,
+			Modules: map[string]config.ProbeModule{"prod": {}},
+		},
+	}
+	h, err := NewProbeHandler(cfg, docker.NewClientPool(cfg.Collection.Timeout, time.Minute))
+	require.NoError(t, err)
+	return h
+}
+
+// fakeDaemon answers the few Docker API calls a probe with only the container
+// collector makes, for a daemon running one container.
+func fakeDaemon(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Api-Version", "1.45")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_ping"):
+			_, _ = io.WriteString(w, "OK")
+		case strings.HasSuffix(r.URL.Path, "/containers/json"):
+			_, _ = io.WriteString(w, `[{"Id":"abc123abc123abc123","Names":["/web"],"Image":"nginx:1.27","State":"exited","Status":"Exited (0) 1 hour ago"}]`)
+		case strings.HasSuffix(r.URL.Path, "/containers/abc123abc123abc123/json"):
+			_, _ = io.WriteString(w, `{"Id":"abc123abc123abc123","Name":"/web","State":{"Status":"exited"},"Config":{"Image":"nginx:1.27"},"HostConfig":{}}`)
+		case strings.HasSuffix(r.URL.Path, "/info"):
+			_, _ = io.WriteString(w, `{"MemTotal":8589934592,"NCPU":4}`)
+		case strings.HasSuffix(r.URL.Path, "/networks"):
+			_, _ = io.WriteString(w, `[]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func probe(h *ProbeHandler, query url.Values) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/probe?"+query.Encode(), nil))
+	return rec
+}
+
+func TestProbeHandler_MissingTarget(t *testing.T) {
+	rec := probe(newProbeTestHandler(t), url.Values{})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "target")
+}
+
+func TestProbeHandler_UnknownModule(t *testing.T) {
+	rec := probe(newProbeTestHandler(t), url.Values{"target": {"tcp://127.0.0.1:2375"}, "module": {"staging"}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `unknown module "staging"`)
+}
+
+func TestProbeHandler_UnreachableTarget(t *testing.T) {
+	// A closed listener refuses connections right away
+	srv := httptest.NewServer(http.NotFoundHandler())
+	target := "tcp://" + srv.Listener.Addr().String()
+	srv.Close()
+
+	rec := probe(newProbeTestHandler(t), url.Values{"target": {target}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "probe_success 0")
+	assert.NotContains(t, body, "\ncontainer_", "no collector runs against an unreachable daemon")
+}
+
+func TestProbeHandler_ValidTarget(t *testing.T) {
+	srv := fakeDaemon(t)
+	target := "tcp://" + srv.Listener.Addr().String()
+
+	rec := probe(newProbeTestHandler(t), url.Values{"target": {target}, "module": {"PROD"}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "probe_success 1")
+	assert.Contains(t, body, `container_info{`)
+	assert.Contains(t, body, `container_name="web"`)
+}
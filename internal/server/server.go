@@ -17,11 +17,12 @@ import (
 // Server is the HTTP server that serves metrics and health endpoints.
 type Server struct {
 	httpServer *http.Server
+	mux        *http.ServeMux
 	cfg        config.ServerConfig
 }
 
 // NewServer creates a configured HTTP server.
-func NewServer(cfg config.ServerConfig, registry *prometheus.Registry, dockerClient *docker.Client) *Server {
+func NewServer(cfg config.ServerConfig, registry *prometheus.Registry, dockerClients []*docker.Client) *Server {
 	mux := http.NewServeMux()
 
 	// Metrics endpoint
@@ -31,7 +32,7 @@ func NewServer(cfg config.ServerConfig, registry *prometheus.Registry, dockerCli
 
 	// Health, ready, version
 	mux.Handle(cfg.HealthPath, healthHandler())
-	mux.Handle(cfg.ReadyPath, readyHandler(dockerClient))
+	mux.Handle(cfg.ReadyPath, readyHandler(dockerClients))
 	mux.Handle("/version", versionHandler())
 
 	// Apply middleware stack: recovery → logging → (optional auth) → routes
@@ -45,6 +46,7 @@ func NewServer(cfg config.ServerConfig, registry *prometheus.Registry, dockerCli
 	addr := fmt.Sprintf("%s:%s", cfg.Address, cfg.Port)
 	return &Server{
 		cfg: cfg,
+		mux: mux,
 		httpServer: &http.Server{
 			Addr:         addr,
 			Handler:      handler,
@@ -55,6 +57,12 @@ func NewServer(cfg config.ServerConfig, registry *prometheus.Registry, dockerCli
 	}
 }
 
+// Handle registers an additional route. It goes through the same middleware
+// stack as the built-in ones and must be called before Start.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
 // Start begins listening. It blocks until the server is shut down.
 func (s *Server) Start() error {
 	log.WithField("addr", s.httpServer.Addr).Info("Starting HTTP server")
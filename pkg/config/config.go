@@ -17,6 +17,8 @@ type Config struct {
 	Metrics     MetricsConfig     `mapstructure:"metrics"`
 	Logging     LoggingConfig     `mapstructure:"logging"`
 	Performance PerformanceConfig `mapstructure:"performance"`
+	Host        HostConfig        `mapstructure:"host"`
+	Probe       ProbeConfig       `mapstructure:"probe"`
 }
 
 type ServerConfig struct {
@@ -42,9 +44,49 @@ type AuthConfig struct {
 }
 
 type DockerConfig struct {
+	Host       string           `mapstructure:"host"`
+	APIVersion string           `mapstructure:"api_version"`
+	TLS        DockerTLSConfig  `mapstructure:"tls"`
+	Endpoints  []DockerEndpoint `mapstructure:"endpoints"`
+}
+
+// DockerEndpoint is one named daemon when the exporter watches several. Its
+// name becomes the docker_host label on every metric collected from it.
+// Filters, when set, replace collection.filters for this endpoint.
+type DockerEndpoint struct {
+	Name       string          `mapstructure:"name"`
 	Host       string          `mapstructure:"host"`
 	APIVersion string          `mapstructure:"api_version"`
 	TLS        DockerTLSConfig `mapstructure:"tls"`
+	Filters    *FiltersConfig  `mapstructure:"filters"`
+}
+
+// DockerConfig returns the connection settings of the endpoint.
+func (e DockerEndpoint) DockerConfig() DockerConfig {
+	return DockerConfig{
+		Host:       e.Host,
+		APIVersion: e.APIVersion,
+		TLS:        e.TLS,
+	}
+}
+
+// MultiHost reports whether named endpoints are configured. In that case
+// docker.host is ignored and metrics carry a docker_host label.
+func (c DockerConfig) MultiHost() bool {
+	return len(c.Endpoints) > 0
+}
+
+// ResolvedEndpoints returns the endpoints to monitor: the configured list, or
+// a single unnamed endpoint built from docker.host.
+func (c DockerConfig) ResolvedEndpoints() []DockerEndpoint {
+	if c.MultiHost() {
+		return c.Endpoints
+	}
+	return []DockerEndpoint{{
+		Host:       c.Host,
+		APIVersion: c.APIVersion,
+		TLS:        c.TLS,
+	}}
 }
 
 type DockerTLSConfig struct {
@@ -56,15 +98,86 @@ type DockerTLSConfig struct {
 }
 
 type CollectionConfig struct {
-	Interval   time.Duration    `mapstructure:"interval"`
-	Timeout    time.Duration    `mapstructure:"timeout"`
-	Collectors CollectorsConfig `mapstructure:"collectors"`
-	Filters    FiltersConfig    `mapstructure:"filters"`
+	Interval      time.Duration       `mapstructure:"interval"`
+	Timeout       time.Duration       `mapstructure:"timeout"`
+	Collectors    CollectorsConfig    `mapstructure:"collectors"`
+	Filters       FiltersConfig       `mapstructure:"filters"`
+	Inventory     InventoryConfig     `mapstructure:"inventory"`
+	Stats         StatsConfig         `mapstructure:"stats"`
+	Images        ImagesConfig        `mapstructure:"images"`
+	DiskUsage     DiskUsageConfig     `mapstructure:"disk_usage"`
+	ContainerSize ContainerSizeConfig `mapstructure:"container_size"`
 }
 
 type CollectorsConfig struct {
-	Container bool `mapstructure:"container"`
-	System    bool `mapstructure:"system"`
+	Container     bool `mapstructure:"container"`
+	System        bool `mapstructure:"system"`
+	Swarm         bool `mapstructure:"swarm"`
+	Images        bool `mapstructure:"images"`
+	Networks      bool `mapstructure:"networks"`
+	DiskUsage     bool `mapstructure:"disk_usage"`
+	ContainerSize bool `mapstructure:"container_size"`
+	OOMEvents     bool `mapstructure:"oom_events"`
+	Events        bool `mapstructure:"events"`
+	Pressure      bool `mapstructure:"pressure"`
+	Sockets       bool `mapstructure:"sockets"`
+}
+
+// ContainerSizeConfig controls the container filesystem size collector.
+// Sizes are listed in the background on their own interval because the
+// daemon walks each container's layers to compute them.
+type ContainerSizeConfig struct {
+	Interval time.Duration `mapstructure:"interval"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+// DiskUsageConfig controls the disk usage (`docker system df`) collector,
+// which refreshes in the background on its own interval because the call
+// walks every layer and volume on disk.
+type DiskUsageConfig struct {
+	Interval time.Duration `mapstructure:"interval"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+// ImagesConfig controls the per-image collector. Allow and deny are regex
+// patterns matched against "repository:tag"; deny wins. MaxImages caps the
+// number of images reported, keeping the largest; 0 disables the cap.
+type ImagesConfig struct {
+	Allow     []string `mapstructure:"allow"`
+	Deny      []string `mapstructure:"deny"`
+	MaxImages int      `mapstructure:"max_images"`
+}
+
+// InventoryConfig controls the event-driven container inventory. When enabled,
+// containers are listed once and kept current from the Docker events stream
+// instead of being listed and inspected on every scrape.
+type InventoryConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// Stats sources for StatsConfig.Source.
+const (
+	StatsSourceOneshot = "oneshot"
+	StatsSourceStream  = "stream"
+	StatsSourceCgroup  = "cgroup"
+)
+
+// StatsConfig selects where per-container resource stats come from.
+// "oneshot" opens a stream=false stats request per container per scrape;
+// "stream" keeps one stream=true reader per running container; "cgroup"
+// reads the cgroup filesystem under host.cgroup_root directly. PerCPU adds a
+// per-core CPU usage breakdown.
+type StatsConfig struct {
+	Source     string           `mapstructure:"source"`
+	PerCPU     bool             `mapstructure:"per_cpu"`
+	MemoryStat MemoryStatConfig `mapstructure:"memory_stat"`
+}
+
+// MemoryStatConfig controls the container_memory_stat family. Keys is an
+// allowlist of memory.stat keys, by cgroup v2 name; empty exports every key.
+type MemoryStatConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Keys    []string `mapstructure:"keys"`
 }
 
 type FiltersConfig struct {
@@ -95,6 +208,47 @@ type LoggingConfig struct {
 	Output string `mapstructure:"output"`
 }
 
+// HostConfig locates host filesystems bind-mounted into the exporter, for
+// sources that read them directly instead of going through the Docker API.
+// DevBlockRoot is /sys/dev/block, used to name block devices; empty disables
+// name resolution. ProcRoot is /proc, used by the socket collector.
+type HostConfig struct {
+	CgroupRoot   string `mapstructure:"cgroup_root"`
+	DevBlockRoot string `mapstructure:"dev_block_root"`
+	ProcRoot     string `mapstructure:"proc_root"`
+}
+
+// ProbeConfig enables the multi-target /probe endpoint, which collects from a
+// Docker daemon named in the request instead of the configured one.
+type ProbeConfig struct {
+	Enabled     bool                   `mapstructure:"enabled"`
+	Path        string                 `mapstructure:"path"`
+	IdleTimeout time.Duration          `mapstructure:"idle_timeout"`
+	Modules     map[string]ProbeModule `mapstructure:"modules"`
+}
+
+// DefaultProbeModule is used when a probe request names no module.
+const DefaultProbeModule = "default"
+
+// ProbeModule holds the connection settings and filters applied to a probe
+// target, selected with the module query parameter. Filters, when set,
+// replace collection.filters.
+type ProbeModule struct {
+	APIVersion string          `mapstructure:"api_version"`
+	TLS        DockerTLSConfig `mapstructure:"tls"`
+	Filters    *FiltersConfig  `mapstructure:"filters"`
+}
+
+// DockerConfig returns the connection settings for a target probed with this
+// module.
+func (m ProbeModule) DockerConfig(target string) DockerConfig {
+	return DockerConfig{
+		Host:       target,
+		APIVersion: m.APIVersion,
+		TLS:        m.TLS,
+	}
+}
+
 type PerformanceConfig struct {
 	MaxConcurrent int  `mapstructure:"max_concurrent"`
 	Workers       int  `mapstructure:"workers"`
@@ -123,6 +277,30 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("collection.timeout", "30s")
 	v.SetDefault("collection.collectors.container", true)
 	v.SetDefault("collection.collectors.system", true)
+	v.SetDefault("collection.collectors.swarm", false)
+	v.SetDefault("collection.collectors.images", false)
+	v.SetDefault("collection.collectors.networks", false)
+	v.SetDefault("collection.collectors.disk_usage", false)
+	v.SetDefault("collection.collectors.container_size", false)
+	v.SetDefault("collection.collectors.oom_events", false)
+	v.SetDefault("collection.collectors.events", false)
+	v.SetDefault("collection.collectors.pressure", false)
+	v.SetDefault("collection.collectors.sockets", false)
+	v.SetDefault("collection.inventory.enabled", false)
+	v.SetDefault("collection.stats.source", StatsSourceOneshot)
+	v.SetDefault("collection.stats.per_cpu", false)
+	v.SetDefault("collection.stats.memory_stat.enabled", false)
+	v.SetDefault("collection.stats.memory_stat.keys", []string{
+		"anon", "file", "kernel_stack", "slab", "sock", "shmem",
+		"file_mapped", "file_dirty", "file_writeback", "anon_thp",
+		"active_anon", "inactive_anon", "active_file", "inactive_file", "unevictable",
+		"pgfault", "pgmajfault",
+	})
+	v.SetDefault("collection.images.max_images", 500)
+	v.SetDefault("collection.disk_usage.interval", "5m")
+	v.SetDefault("collection.disk_usage.timeout", "2m")
+	v.SetDefault("collection.container_size.interval", "5m")
+	v.SetDefault("collection.container_size.timeout", "1m")
 
 	// Metrics
 	v.SetDefault("metrics.namespace", "")
@@ -138,6 +316,16 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("performance.max_concurrent", 10)
 	v.SetDefault("performance.workers", 4)
 	v.SetDefault("performance.pprof_enabled", false)
+
+	// Host
+	v.SetDefault("host.cgroup_root", "/sys/fs/cgroup")
+	v.SetDefault("host.dev_block_root", "/sys/dev/block")
+	v.SetDefault("host.proc_root", "/proc")
+
+	// Probe
+	v.SetDefault("probe.enabled", false)
+	v.SetDefault("probe.path", "/probe")
+	v.SetDefault("probe.idle_timeout", "5m")
 }
 
 // bindEnvVars maps environment variables to config keys.
@@ -153,6 +341,10 @@ func bindEnvVars(v *viper.Viper) {
 		"docker.api_version":         "DOCKER_API_VERSION",
 		"collection.interval":        "COLLECTION_INTERVAL",
 		"collection.timeout":         "COLLECTION_TIMEOUT",
+		"collection.stats.source":    "STATS_SOURCE",
+		"host.cgroup_root":           "HOST_CGROUP_ROOT",
+		"host.dev_block_root":        "HOST_DEV_BLOCK_ROOT",
+		"host.proc_root":             "HOST_PROC_ROOT",
 		"logging.level":              "LOG_LEVEL",
 		"logging.format":             "LOG_FORMAT",
 		"performance.max_concurrent": "MAX_CONCURRENT",
@@ -203,9 +395,22 @@ func (c *Config) Validate() error {
 	if c.Server.Port == "" {
 		return fmt.Errorf("server.port is required")
 	}
-	if c.Docker.Host == "" {
+	if c.Docker.Host == "" && !c.Docker.MultiHost() {
 		return fmt.Errorf("docker.host is required")
 	}
+	seen := make(map[string]bool, len(c.Docker.Endpoints))
+	for i, ep := range c.Docker.Endpoints {
+		if ep.Name == "" {
+			return fmt.Errorf("docker.endpoints[%d].name is required", i)
+		}
+		if seen[ep.Name] {
+			return fmt.Errorf("docker.endpoints: duplicate name %q", ep.Name)
+		}
+		seen[ep.Name] = true
+		if ep.Host == "" {
+			return fmt.Errorf("docker.endpoints[%d].host is required", i)
+		}
+	}
 	if c.Server.Auth.Enabled {
 		if c.Server.Auth.Username == "" || c.Server.Auth.Password == "" {
 			return fmt.Errorf("auth username and password are required when auth is enabled")
@@ -216,6 +421,64 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("TLS cert_file and key_file are required when TLS is enabled")
 		}
 	}
+	if c.Collection.Interval < 0 {
+		return fmt.Errorf("collection.interval must be >= 0")
+	}
+	switch c.Collection.Stats.Source {
+	case "", StatsSourceOneshot, StatsSourceStream:
+	case StatsSourceCgroup:
+		if c.Host.CgroupRoot == "" {
+			return fmt.Errorf("host.cgroup_root is required when collection.stats.source is %q", StatsSourceCgroup)
+		}
+		if len(c.Docker.Endpoints) > 1 {
+			return fmt.Errorf("collection.stats.source %q reads the local host and cannot be used with several docker.endpoints", StatsSourceCgroup)
+		}
+	default:
+		return fmt.Errorf("collection.stats.source must be one of %q, %q, %q", StatsSourceOneshot, StatsSourceStream, StatsSourceCgroup)
+	}
+	if c.Collection.Collectors.Pressure {
+		if c.Host.CgroupRoot == "" {
+			return fmt.Errorf("host.cgroup_root is required when collection.collectors.pressure is enabled")
+		}
+		if len(c.Docker.Endpoints) > 1 {
+			return fmt.Errorf("collection.collectors.pressure reads the local host and cannot be used with several docker.endpoints")
+		}
+	}
+	if c.Collection.Collectors.Sockets {
+		if c.Host.ProcRoot == "" {
+			return fmt.Errorf("host.proc_root is required when collection.collectors.sockets is enabled")
+		}
+		if len(c.Docker.Endpoints) > 1 {
+			return fmt.Errorf("collection.collectors.sockets reads the local host and cannot be used with several docker.endpoints")
+		}
+	}
+	if c.Collection.Collectors.DiskUsage {
+		if c.Collection.DiskUsage.Interval <= 0 {
+			return fmt.Errorf("collection.disk_usage.interval must be > 0")
+		}
+		if c.Collection.DiskUsage.Timeout <= 0 {
+			return fmt.Errorf("collection.disk_usage.timeout must be > 0")
+		}
+	}
+	if c.Collection.Collectors.ContainerSize {
+		if c.Collection.ContainerSize.Interval <= 0 {
+			return fmt.Errorf("collection.container_size.interval must be > 0")
+		}
+		if c.Collection.ContainerSize.Timeout <= 0 {
+			return fmt.Errorf("collection.container_size.timeout must be > 0")
+		}
+	}
+	if c.Collection.Images.MaxImages < 0 {
+		return fmt.Errorf("collection.images.max_images must be >= 0")
+	}
+	if c.Probe.Enabled {
+		if c.Probe.Path == "" {
+			return fmt.Errorf("probe.path is required when probe is enabled")
+		}
+		if c.Probe.IdleTimeout <= 0 {
+			return fmt.Errorf("probe.idle_timeout must be > 0")
+		}
+	}
 	if c.Performance.MaxConcurrent < 1 {
 		return fmt.Errorf("performance.max_concurrent must be >= 1")
 	}
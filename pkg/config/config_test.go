@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -24,6 +25,9 @@ func TestLoad_Defaults(t *testing.T) {
 	assert.True(t, cfg.Metrics.Cache.Enabled)
 	assert.True(t, cfg.Collection.Collectors.Container)
 	assert.True(t, cfg.Collection.Collectors.System)
+	assert.False(t, cfg.Collection.Collectors.Swarm)
+	assert.False(t, cfg.Collection.Stats.MemoryStat.Enabled)
+	assert.Contains(t, cfg.Collection.Stats.MemoryStat.Keys, "shmem")
 }
 
 func TestLoad_ConfigFile(t *testing.T) {
@@ -56,6 +60,108 @@ performance:
 	assert.Equal(t, 8, cfg.Performance.Workers)
 }
 
+func TestLoad_Endpoints(t *testing.T) {
+	content := `
+docker:
+  endpoints:
+    - name: edge-1
+      host: "tcp://10.0.0.5:2376"
+      tls:
+        enabled: true
+        ca_cert: "/certs/edge-1/ca.pem"
+        cert: "/certs/edge-1/cert.pem"
+        key: "/certs/edge-1/key.pem"
+    - name: edge-2
+      host: "tcp://10.0.0.6:2376"
+      filters:
+        exclude:
+          names: ["^test-.*"]
+`
+	tmpDir := t.TempDir()
+	cfgFile := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(cfgFile, []byte(content), 0644))
+
+	cfg, err := Load(cfgFile)
+	require.NoError(t, err)
+
+	require.True(t, cfg.Docker.MultiHost())
+	eps := cfg.Docker.ResolvedEndpoints()
+	require.Len(t, eps, 2)
+
+	assert.Equal(t, "edge-1", eps[0].Name)
+	assert.Equal(t, "tcp://10.0.0.5:2376", eps[0].DockerConfig().Host)
+	assert.True(t, eps[0].TLS.Enabled)
+	assert.Equal(t, "/certs/edge-1/ca.pem", eps[0].TLS.CACert)
+	assert.Nil(t, eps[0].Filters)
+
+	require.NotNil(t, eps[1].Filters)
+	assert.Equal(t, []string{"^test-.*"}, eps[1].Filters.Exclude.Names)
+}
+
+func TestResolvedEndpoints_SingleHost(t *testing.T) {
+	cfg, err := Load("")
+	require.NoError(t, err)
+
+	assert.False(t, cfg.Docker.MultiHost())
+	eps := cfg.Docker.ResolvedEndpoints()
+	require.Len(t, eps, 1)
+	assert.Equal(t, "", eps[0].Name)
+	assert.Equal(t, "unix:///var/run/docker.sock", eps[0].Host)
+}
+
+func TestValidate_Endpoints(t *testing.T) {
+	base := func(eps ...DockerEndpoint) *Config {
+		return &Config{
+			Server:      ServerConfig{Port: "9200"},
+			Docker:      DockerConfig{Endpoints: eps},
+			Performance: PerformanceConfig{MaxConcurrent: 1, Workers: 1},
+		}
+	}
+
+	assert.NoError(t, base(DockerEndpoint{Name: "a", Host: "tcp://a:2376"}).Validate())
+	assert.Error(t, base(DockerEndpoint{Host: "tcp://a:2376"}).Validate(), "missing name")
+	assert.Error(t, base(DockerEndpoint{Name: "a"}).Validate(), "missing host")
+	assert.Error(t, base(
+		DockerEndpoint{Name: "a", Host: "tcp://a:2376"},
+		DockerEndpoint{Name: "a", Host: "tcp://b:2376"},
+	).Validate(), "duplicate name")
+}
+
+func TestLoad_ProbeModules(t *testing.T) {
+	content := `
+probe:
+  enabled: true
+  modules:
+    prod:
+      tls:
+        enabled: true
+        ca_cert: "/certs/prod/ca.pem"
+      filters:
+        include:
+          labels: ["env=prod"]
+`
+	tmpDir := t.TempDir()
+	cfgFile := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(cfgFile, []byte(content), 0644))
+
+	cfg, err := Load(cfgFile)
+	require.NoError(t, err)
+
+	assert.True(t, cfg.Probe.Enabled)
+	assert.Equal(t, "/probe", cfg.Probe.Path)
+	assert.Equal(t, 5*time.Minute, cfg.Probe.IdleTimeout)
+
+	require.Contains(t, cfg.Probe.Modules, "prod")
+	module := cfg.Probe.Modules["prod"]
+	require.NotNil(t, module.Filters)
+	assert.Equal(t, []string{"env=prod"}, module.Filters.Include.Labels)
+
+	dc := module.DockerConfig("tcp://10.0.0.5:2376")
+	assert.Equal(t, "tcp://10.0.0.5:2376", dc.Host)
+	assert.True(t, dc.TLS.Enabled)
+	assert.Equal(t, "/certs/prod/ca.pem", dc.TLS.CACert)
+}
+
 func TestLoad_EnvOverrides(t *testing.T) {
 	t.Setenv("EXPORTER_PORT", "9999")
 	t.Setenv("DOCKER_HOST", "tcp://remote:2375")
@@ -102,6 +208,70 @@ func TestValidate_InvalidPerformance(t *testing.T) {
 	assert.Error(t, cfg.Validate())
 }
 
+func TestValidate_NegativeInterval(t *testing.T) {
+	cfg := &Config{
+		Server:      ServerConfig{Port: "9200"},
+		Docker:      DockerConfig{Host: "unix:///var/run/docker.sock"},
+		Collection:  CollectionConfig{Interval: -1 * time.Second},
+		Performance: PerformanceConfig{MaxConcurrent: 1, Workers: 1},
+	}
+	assert.Error(t, cfg.Validate())
+}
+
+func TestValidate_StatsSource(t *testing.T) {
+	cfg := &Config{
+		Server:      ServerConfig{Port: "9200"},
+		Docker:      DockerConfig{Host: "unix:///var/run/docker.sock"},
+		Collection:  CollectionConfig{Stats: StatsConfig{Source: "bogus"}},
+		Performance: PerformanceConfig{MaxConcurrent: 1, Workers: 1},
+	}
+	assert.Error(t, cfg.Validate())
+
+	cfg.Collection.Stats.Source = StatsSourceCgroup
+	assert.Error(t, cfg.Validate(), "cgroup source needs a cgroup root")
+
+	cfg.Host.CgroupRoot = "/sys/fs/cgroup"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_Pressure(t *testing.T) {
+	cfg := &Config{
+		Server:      ServerConfig{Port: "9200"},
+		Docker:      DockerConfig{Host: "unix:///var/run/docker.sock"},
+		Collection:  CollectionConfig{Collectors: CollectorsConfig{Pressure: true}},
+		Performance: PerformanceConfig{MaxConcurrent: 1, Workers: 1},
+	}
+	assert.Error(t, cfg.Validate(), "pressure needs a cgroup root")
+
+	cfg.Host.CgroupRoot = "/sys/fs/cgroup"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Docker.Endpoints = []DockerEndpoint{
+		{Name: "a", Host: "unix:///var/run/docker.sock"},
+		{Name: "b", Host: "tcp://remote:2376"},
+	}
+	assert.Error(t, cfg.Validate(), "pressure reads the local host only")
+}
+
+func TestValidate_Sockets(t *testing.T) {
+	cfg := &Config{
+		Server:      ServerConfig{Port: "9200"},
+		Docker:      DockerConfig{Host: "unix:///var/run/docker.sock"},
+		Collection:  CollectionConfig{Collectors: CollectorsConfig{Sockets: true}},
+		Performance: PerformanceConfig{MaxConcurrent: 1, Workers: 1},
+	}
+	assert.Error(t, cfg.Validate(), "sockets needs a proc root")
+
+	cfg.Host.ProcRoot = "/host/proc"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Docker.Endpoints = []DockerEndpoint{
+		{Name: "a", Host: "unix:///var/run/docker.sock"},
+		{Name: "b", Host: "tcp://remote:2376"},
+	}
+	assert.Error(t, cfg.Validate(), "sockets reads the local host only")
+}
+
 func TestLoad_MissingConfigFile(t *testing.T) {
 	_, err := Load("/nonexistent/config.yaml")
 	assert.Error(t, err)